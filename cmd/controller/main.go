@@ -31,11 +31,15 @@ import (
 
 	"github.com/spf13/cobra"
 
+	corev1 "k8s.io/api/core/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/informers"
 	coreclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/component-base/cli"
 	cliflag "k8s.io/component-base/cli/flag"
 	"k8s.io/component-base/featuregate"
@@ -52,7 +56,9 @@ import (
 	"k8s.io/dynamic-resource-allocation/leaderelection"
 	"k8s.io/klog/v2"
 
+	mycontroller "github.com/kubernetes-sigs/dra-example-driver/pkg/controller"
 	myclientset "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/clientset/versioned"
+	myinformers "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/informers/externalversions"
 	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
 )
 
@@ -62,6 +68,11 @@ type flags_t struct {
 	kubeAPIBurst *int
 	workers      *int
 
+	pendingClaimRequestTTL        *time.Duration
+	pendingClaimRequestGCInterval *time.Duration
+	reconcileInterval             *time.Duration
+	reconcileDryRun               *bool
+
 	httpEndpoint *string
 	metricsPath  *string
 	profilePath  *string
@@ -114,6 +125,16 @@ func newCommand() *cobra.Command {
 			return err
 		}
 
+		if *flags.pendingClaimRequestTTL <= 0 {
+			return fmt.Errorf("--pending-claim-request-ttl must be positive, got %v", *flags.pendingClaimRequestTTL)
+		}
+		if *flags.pendingClaimRequestGCInterval <= 0 {
+			return fmt.Errorf("--pending-claim-request-gc-interval must be positive, got %v", *flags.pendingClaimRequestGCInterval)
+		}
+		if *flags.reconcileInterval < 0 {
+			return fmt.Errorf("--reconcile-interval must not be negative, got %v", *flags.reconcileInterval)
+		}
+
 		return nil
 	}
 
@@ -189,6 +210,16 @@ func addFlags(cmd *cobra.Command, logsconfig *logsapi.LoggingConfiguration, fgat
 	flags.kubeAPIBurst = fs.Int("kube-api-burst", 10, "Burst to use while communicating with the kubernetes apiserver.")
 	flags.workers = fs.Int("workers", 10, "Concurrency to process multiple claims")
 
+	fs = sharedFlagSets.FlagSet("allocation")
+	flags.pendingClaimRequestTTL = fs.Duration("pending-claim-request-ttl", 5*time.Minute,
+		"How long a tentative device selection made for a potential node is kept without being refreshed by the scheduler.")
+	flags.pendingClaimRequestGCInterval = fs.Duration("pending-claim-request-gc-interval", time.Minute,
+		"Interval for dropping expired pending claim requests and requests of deleted or already allocated claims.")
+	flags.reconcileInterval = fs.Duration("reconcile-interval", 5*time.Minute,
+		"Interval for freeing devices allocated to ResourceClaims that no longer exist or are not allocated on that node. An allocation is freed after being found orphaned twice. Zero disables the reconciler.")
+	flags.reconcileDryRun = fs.Bool("reconcile-dry-run", false,
+		"Only report orphaned allocations found by the reconciler instead of freeing them.")
+
 	fs = sharedFlagSets.FlagSet("http server")
 	flags.httpEndpoint = fs.String("http-endpoint", "",
 		"The TCP network address where the HTTP server for diagnostics, including pprof, metrics and (if applicable) leader election health check, will listen (example: `:8080`). The default is the empty string, which means the server is disabled.")
@@ -341,9 +372,49 @@ func startControllerWithLeader(config *config_t) error {
 
 func StartController(config *config_t) {
 	klog.V(3).Infof("Starting controller without leader election")
-	driver := newDriver(config)
-	informerFactory := informers.NewSharedInformerFactory(config.clientset.core, 0 /* resync period */)
-	ctrl := controller.New(config.ctx, mycrd.ApiGroupName, driver, config.clientset.core, informerFactory)
-	informerFactory.Start(config.ctx.Done())
+	informerFactories := &mycontroller.Informers{
+		Core:                 informers.NewSharedInformerFactory(config.clientset.core, 0 /* resync period */),
+		Example:              myinformers.NewSharedInformerFactoryWithOptions(config.clientset.example, 0 /* resync period */, myinformers.WithNamespace(config.namespace)),
+		ExampleAllNamespaces: myinformers.NewSharedInformerFactory(config.clientset.example, 0 /* resync period */),
+	}
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: config.clientset.core.CoreV1().Events("")})
+	defer eventBroadcaster.Shutdown()
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: mycrd.ApiGroupName + "-controller"})
+
+	driverConfig := &mycontroller.Config{
+		Namespace:              config.namespace,
+		Clientset:              config.clientset.example,
+		CoreClient:             config.clientset.core,
+		PendingClaimRequestTTL: *config.flags.pendingClaimRequestTTL,
+	}
+	driver, err := mycontroller.NewDriver(driverConfig, recorder, informerFactories)
+	if err != nil {
+		klog.Errorf("Failed to create driver: %v", err)
+		return
+	}
+	mycontroller.RegisterMetrics(driver)
+	ctrl := controller.New(config.ctx, mycrd.ApiGroupName, driver, config.clientset.core, informerFactories.Core)
+	informerFactories.Start(config.ctx.Done())
+
+	klog.V(3).Infof("Waiting for informer caches to sync")
+	err = informerFactories.WaitForCacheSync(config.ctx.Done())
+	if err != nil {
+		klog.Errorf("%v", err)
+		return
+	}
+
+	// continue with the devices the previous leader picked for pending claims
+	err = driver.RestorePendingClaimRequests()
+	if err != nil {
+		klog.Errorf("Error restoring pending claim requests: %v", err)
+	}
+
+	go driver.RunPendingClaimRequestsCollector(config.ctx, *config.flags.pendingClaimRequestGCInterval)
+	if *config.flags.reconcileInterval > 0 {
+		go mycontroller.NewOrphanReconciler(driver, *config.flags.reconcileDryRun).Run(config.ctx, *config.flags.reconcileInterval)
+	}
+	go driver.RunQuotaStatusSync(config.ctx, mycontroller.QuotaStatusSyncInterval)
+
 	ctrl.Run(*config.flags.workers)
 }
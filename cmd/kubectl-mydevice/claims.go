@@ -0,0 +1,143 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	resourcev1alpha1 "k8s.io/api/resource/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+const (
+	claimStateAllocated = "Allocated"
+	claimStateRequested = "Requested"
+)
+
+// claimRow is one claim on one node
+type claimRow struct {
+	namespace  string
+	name       string
+	uid        string
+	node       string
+	state      string
+	devices    []string
+	cdiDevices []string
+}
+
+func newClaimsCommand(config *config_t) *cobra.Command {
+	return &cobra.Command{
+		Use:   "claims",
+		Short: "Show the devices of each claim",
+		Long: `Show the devices of each claim.
+
+Claims are Allocated once the controller allocated devices to them, and
+Requested while the controller picked devices for them on a potential node
+during scheduling but did not allocate them yet. Claims whose ResourceClaim no longer exists are shown with an
+unknown namespace and name.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mass, err := listMAS(cmd.Context(), config)
+			if err != nil {
+				return err
+			}
+			claims, err := listClaims(cmd.Context(), config)
+			if err != nil {
+				return err
+			}
+			return printClaims(cmd.OutOrStdout(), claimRows(mass, claims))
+		},
+	}
+}
+
+// Rows for all allocations and requests of all nodes, sorted by claim name
+func claimRows(mass []*mycrd.MydeviceAllocationState, claims map[types.UID]*resourcev1alpha1.ResourceClaim) []claimRow {
+	var rows []claimRow
+
+	for _, mas := range mass {
+		for claimUID, allocation := range mas.Spec.ResourceClaimAllocations {
+			row := newClaimRow(claims, claimUID, mas.Name, claimStateAllocated)
+			for _, device := range allocation {
+				row.devices = append(row.devices, device.UID)
+				row.cdiDevices = append(row.cdiDevices, qualifiedCDIDevice(device.CDIDevice))
+			}
+			rows = append(rows, row)
+		}
+
+		for claimUID, request := range mas.Spec.ResourceClaimRequests {
+			if _, allocated := mas.Spec.ResourceClaimAllocations[claimUID]; allocated {
+				continue
+			}
+			row := newClaimRow(claims, claimUID, mas.Name, claimStateRequested)
+			for _, device := range request.Mydevices {
+				row.devices = append(row.devices, device.UID)
+				row.cdiDevices = append(row.cdiDevices, qualifiedCDIDevice(mas.Spec.AllocatableMydevices[device.UID].CDIDevice))
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].namespace != rows[j].namespace {
+			return rows[i].namespace < rows[j].namespace
+		}
+		if rows[i].name != rows[j].name {
+			return rows[i].name < rows[j].name
+		}
+		if rows[i].uid != rows[j].uid {
+			return rows[i].uid < rows[j].uid
+		}
+		return rows[i].node < rows[j].node
+	})
+
+	return rows
+}
+
+func newClaimRow(claims map[types.UID]*resourcev1alpha1.ResourceClaim, claimUID, node, state string) claimRow {
+	namespace, name := claimName(claims, claimUID)
+	return claimRow{
+		namespace: namespace,
+		name:      name,
+		uid:       claimUID,
+		node:      node,
+		state:     state,
+	}
+}
+
+func printClaims(out io.Writer, rows []claimRow) error {
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tUID\tNODE\tSTATE\tDEVICES\tCDI DEVICES")
+
+	for _, row := range rows {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
+			row.namespace,
+			row.name,
+			row.uid,
+			row.node,
+			row.state,
+			joinOrNone(row.devices),
+			joinOrNone(row.cdiDevices))
+	}
+
+	return w.Flush()
+}
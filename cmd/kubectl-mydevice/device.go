@@ -0,0 +1,156 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/cobra"
+	resourcev1alpha1 "k8s.io/api/resource/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+func newDeviceCommand(config *config_t) *cobra.Command {
+	return &cobra.Command{
+		Use:   "device UID",
+		Short: "Show which claims hold a device",
+		Long: `Show which claims hold a device.
+
+Device UIDs are only unique per node, fake devices for instance are named the
+same on all nodes, so the device is shown for every node it is found on.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mass, err := listMAS(cmd.Context(), config)
+			if err != nil {
+				return err
+			}
+			claims, err := listClaims(cmd.Context(), config)
+			if err != nil {
+				return err
+			}
+			return printDevice(cmd.OutOrStdout(), args[0], mass, claims)
+		},
+	}
+}
+
+func printDevice(out io.Writer, deviceUID string, mass []*mycrd.MydeviceAllocationState, claims map[types.UID]*resourcev1alpha1.ResourceClaim) error {
+	found := false
+
+	for _, mas := range mass {
+		allocatedTo := deviceClaims(deviceUID, claims, allocatedDeviceUIDs(mas.Spec.ResourceClaimAllocations))
+		requestedBy := deviceClaims(deviceUID, claims, pendingDeviceUIDs(mas.Spec))
+
+		device, allocatable := mas.Spec.AllocatableMydevices[deviceUID]
+		// a device that disappeared from the node can still be held by claims
+		if !allocatable && len(allocatedTo) == 0 && len(requestedBy) == 0 {
+			continue
+		}
+
+		if found {
+			fmt.Fprintln(out)
+		}
+		found = true
+
+		fmt.Fprintf(out, "Node:         %v\n", mas.Name)
+		fmt.Fprintf(out, "UID:          %v\n", deviceUID)
+		if allocatable {
+			health := device.Health
+			if health == "" {
+				health = mycrd.MydeviceHealthy
+			}
+			fmt.Fprintf(out, "Type:         %v\n", device.Type)
+			fmt.Fprintf(out, "PCI address:  %v\n", valueOrNone(device.PCIAddress))
+			fmt.Fprintf(out, "CDI device:   %v\n", qualifiedCDIDevice(device.CDIDevice))
+			fmt.Fprintf(out, "Health:       %v\n", health)
+			fmt.Fprintf(out, "Cordoned:     %v\n", device.Cordoned)
+			// limit of the device alone, the resource class limit applies on top
+			fmt.Fprintf(out, "Max sharers:  %v\n", mycrd.EffectiveMaxSharers(&device, 0))
+		} else {
+			fmt.Fprintf(out, "Allocatable:  false\n")
+		}
+		printDeviceClaims(out, "Allocated to:", allocatedTo)
+		printDeviceClaims(out, "Requested by:", requestedBy)
+	}
+
+	if !found {
+		return fmt.Errorf("device %v not found on any node", deviceUID)
+	}
+	return nil
+}
+
+// Device UIDs by claim UID of the allocations
+func allocatedDeviceUIDs(allocations map[string]mycrd.AllocatedMydevices) map[string][]string {
+	devices := make(map[string][]string)
+	for claimUID, allocation := range allocations {
+		for _, device := range allocation {
+			devices[claimUID] = append(devices[claimUID], device.UID)
+		}
+	}
+	return devices
+}
+
+// Device UIDs by claim UID of the requests that are not allocated yet
+func pendingDeviceUIDs(spec mycrd.MydeviceAllocationStateSpec) map[string][]string {
+	devices := make(map[string][]string)
+	for claimUID, request := range spec.ResourceClaimRequests {
+		if _, allocated := spec.ResourceClaimAllocations[claimUID]; allocated {
+			continue
+		}
+		for _, device := range request.Mydevices {
+			devices[claimUID] = append(devices[claimUID], device.UID)
+		}
+	}
+	return devices
+}
+
+// Sorted "namespace/name (uid)" of the claims holding the device
+func deviceClaims(deviceUID string, claims map[types.UID]*resourcev1alpha1.ResourceClaim, devices map[string][]string) []string {
+	var holders []string
+	for claimUID, deviceUIDs := range devices {
+		for _, uid := range deviceUIDs {
+			if uid == deviceUID {
+				namespace, name := claimName(claims, claimUID)
+				holders = append(holders, fmt.Sprintf("%v/%v (%v)", namespace, name, claimUID))
+				break
+			}
+		}
+	}
+	sort.Strings(holders)
+	return holders
+}
+
+func printDeviceClaims(out io.Writer, title string, holders []string) {
+	if len(holders) == 0 {
+		fmt.Fprintf(out, "%-13v %v\n", title, none)
+		return
+	}
+	fmt.Fprintln(out, title)
+	for _, holder := range holders {
+		fmt.Fprintf(out, "  %v\n", holder)
+	}
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return none
+	}
+	return value
+}
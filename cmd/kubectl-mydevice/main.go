@@ -0,0 +1,162 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	coreclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/component-base/cli"
+	cliflag "k8s.io/component-base/cli/flag"
+	"k8s.io/component-base/featuregate"
+	"k8s.io/component-base/logs"
+	logsapi "k8s.io/component-base/logs/api/v1"
+
+	myclientset "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/clientset/versioned"
+)
+
+type flags_t struct {
+	kubeconfig *string
+	context    *string
+	namespace  *string
+}
+
+type clientset_t struct {
+	core    coreclientset.Interface
+	example myclientset.Interface
+}
+
+type config_t struct {
+	clientset *clientset_t
+	// namespace of the MydeviceAllocationState objects
+	namespace string
+}
+
+func main() {
+	command := newCommand()
+	code := cli.Run(command)
+	os.Exit(code)
+}
+
+func newCommand() *cobra.Command {
+	logsconfig := logsapi.NewLoggingConfiguration()
+	fgate := featuregate.NewFeatureGate()
+	utilruntime.Must(logsapi.AddFeatureGates(fgate))
+
+	cmd := &cobra.Command{
+		Use:   "kubectl-mydevice",
+		Short: "Inspect the Example Mydevice resource-driver state",
+		Long: `Inspect the Example Mydevice resource-driver state.
+
+Reads the MydeviceAllocationState objects kept by the driver and joins the
+claim UIDs in them with the ResourceClaims of the cluster. Installed into
+PATH it runs as "kubectl mydevice".`,
+		SilenceUsage: true,
+	}
+
+	flags := addFlags(cmd, logsconfig, fgate)
+	config := &config_t{}
+
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// Activate logging as soon as possible, after that
+		// show flags with the final logging configuration.
+		if err := logsapi.ValidateAndApply(logsconfig, fgate); err != nil {
+			return err
+		}
+
+		clientconfig := getClientConfig(flags)
+
+		csconfig, err := clientconfig.ClientConfig()
+		if err != nil {
+			return fmt.Errorf("create client configuration: %v", err)
+		}
+
+		coreclient, err := coreclientset.NewForConfig(csconfig)
+		if err != nil {
+			return fmt.Errorf("create core client: %v", err)
+		}
+
+		myclient, err := myclientset.NewForConfig(csconfig)
+		if err != nil {
+			return fmt.Errorf("create Example client: %v", err)
+		}
+
+		namespace, _, err := clientconfig.Namespace()
+		if err != nil {
+			return fmt.Errorf("get namespace: %v", err)
+		}
+
+		config.clientset = &clientset_t{
+			coreclient,
+			myclient,
+		}
+		config.namespace = namespace
+
+		return nil
+	}
+
+	cmd.AddCommand(newNodesCommand(config))
+	cmd.AddCommand(newClaimsCommand(config))
+	cmd.AddCommand(newDeviceCommand(config))
+
+	return cmd
+}
+
+func addFlags(cmd *cobra.Command, logsconfig *logsapi.LoggingConfiguration, fgate featuregate.MutableFeatureGate) *flags_t {
+	flags := &flags_t{}
+
+	sharedFlagSets := cliflag.NamedFlagSets{}
+	fs := sharedFlagSets.FlagSet("logging")
+	logsapi.AddFlags(logsconfig, fs)
+	logs.AddFlags(fs, logs.SkipLoggingConfigurationFlags())
+
+	fs = sharedFlagSets.FlagSet("Kubernetes client")
+	flags.kubeconfig = fs.String("kubeconfig", "", "Path to the kubeconfig file, defaults to KUBECONFIG or ~/.kube/config like kubectl.")
+	flags.context = fs.String("context", "", "The kubeconfig context to use.")
+	flags.namespace = fs.StringP("namespace", "n", "", "Namespace the driver keeps its MydeviceAllocationState objects in, defaults to the namespace of the kubeconfig context.")
+
+	fs = sharedFlagSets.FlagSet("other")
+	fgate.AddFlag(fs)
+
+	// The flag sets only group the flags here, the cobra help is kept
+	// because unlike the flag set help it lists the subcommands
+	fs = cmd.PersistentFlags()
+	for _, f := range sharedFlagSets.FlagSets {
+		fs.AddFlagSet(f)
+	}
+
+	return flags
+}
+
+// getClientConfig loads the kubeconfig the way kubectl does, the plugin
+// is meant to be run by users and not from inside the cluster
+func getClientConfig(f *flags_t) clientcmd.ClientConfig {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = *f.kubeconfig
+
+	overrides := &clientcmd.ConfigOverrides{
+		CurrentContext: *f.context,
+	}
+	overrides.Context.Namespace = *f.namespace
+
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+}
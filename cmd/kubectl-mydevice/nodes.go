@@ -0,0 +1,88 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+func newNodesCommand(config *config_t) *cobra.Command {
+	return &cobra.Command{
+		Use:   "nodes",
+		Short: "Show device counts per node",
+		Long: `Show device counts per node.
+
+ALLOCATABLE devices are all devices the kubelet plugin found, ALLOCATED ones
+are held by at least one claim, FREE ones can take one more claim and
+REQUESTED ones are picked for claims during scheduling but not allocated yet.
+UNHEALTHY and CORDONED devices are not free.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mass, err := listMAS(cmd.Context(), config)
+			if err != nil {
+				return err
+			}
+			return printNodes(cmd.OutOrStdout(), mass)
+		},
+	}
+}
+
+func printNodes(out io.Writer, mass []*mycrd.MydeviceAllocationState) error {
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "NODE\tSTATUS\tALLOCATABLE\tALLOCATED\tFREE\tREQUESTED\tUNHEALTHY\tCORDONED")
+
+	for _, mas := range mass {
+		unhealthy := 0
+		cordoned := 0
+		for _, device := range mas.Spec.AllocatableMydevices {
+			if device.Health == mycrd.MydeviceUnhealthy {
+				unhealthy++
+			}
+			if device.Cordoned {
+				cordoned++
+			}
+		}
+
+		requested := make(map[string]bool)
+		for claimUID, request := range mas.Spec.ResourceClaimRequests {
+			if _, allocated := mas.Spec.ResourceClaimAllocations[claimUID]; allocated {
+				continue
+			}
+			for _, device := range request.Mydevices {
+				requested[device.UID] = true
+			}
+		}
+
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
+			mas.Name,
+			mas.Status,
+			len(mas.Spec.AllocatableMydevices),
+			len(mas.Consumers()),
+			len(mas.Available()),
+			len(requested),
+			unhealthy,
+			cordoned)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	resourcev1alpha1 "k8s.io/api/resource/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	cdiapi "github.com/container-orchestrated-devices/container-device-interface/pkg/cdi"
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+const (
+	cdiVendor = "example.com"
+	cdiClass  = "mydevice"
+
+	// shown for claim UIDs in a MAS without a matching ResourceClaim
+	unknownClaim = "<unknown>"
+	none         = "<none>"
+)
+
+// Get the MydeviceAllocationStates of all nodes, sorted by node name
+func listMAS(ctx context.Context, config *config_t) ([]*mycrd.MydeviceAllocationState, error) {
+	list, err := config.clientset.example.DraV1alpha().MydeviceAllocationStates(config.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list MydeviceAllocationStates in namespace %v: %v", config.namespace, err)
+	}
+
+	mass := make([]*mycrd.MydeviceAllocationState, 0, len(list.Items))
+	for i := range list.Items {
+		mass = append(mass, mycrd.NewMydeviceAllocationStateFromObject(&list.Items[i], config.clientset.example))
+	}
+	sort.Slice(mass, func(i, j int) bool {
+		return mass[i].Name < mass[j].Name
+	})
+	return mass, nil
+}
+
+// Get the ResourceClaims of all namespaces by UID
+func listClaims(ctx context.Context, config *config_t) (map[types.UID]*resourcev1alpha1.ResourceClaim, error) {
+	list, err := config.clientset.core.ResourceV1alpha1().ResourceClaims("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list ResourceClaims: %v", err)
+	}
+
+	claims := make(map[types.UID]*resourcev1alpha1.ResourceClaim, len(list.Items))
+	for i := range list.Items {
+		claims[list.Items[i].UID] = &list.Items[i]
+	}
+	return claims, nil
+}
+
+// Namespace and name of the claim with given UID
+func claimName(claims map[types.UID]*resourcev1alpha1.ResourceClaim, claimUID string) (string, string) {
+	claim, found := claims[types.UID(claimUID)]
+	if !found {
+		return unknownClaim, unknownClaim
+	}
+	return claim.Namespace, claim.Name
+}
+
+// The controller records the CDI device name of the MAS as it is, while the
+// plugin records it qualified, show both the same way
+func qualifiedCDIDevice(cdiDevice string) string {
+	if cdiDevice == "" || cdiapi.IsQualifiedName(cdiDevice) {
+		return cdiDevice
+	}
+	return cdiapi.QualifiedName(cdiVendor, cdiClass, cdiDevice)
+}
+
+func joinOrNone(values []string) string {
+	if len(values) == 0 {
+		return none
+	}
+	return strings.Join(values, ",")
+}
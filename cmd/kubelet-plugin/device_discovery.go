@@ -22,6 +22,7 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
 	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
@@ -29,28 +30,39 @@ import (
 )
 
 const (
-	sysfsDrmDir  = "/sys/class/drm/"
+	sysfsDrmDir  = "class/drm"
 	pciAddressRE = `[0-9a-f]{4}:[0-9a-f]{2}:[0-9a-f]{2}\.[0-7]$`
+	pciRootRE    = `^pci[0-9a-f]{4}:[0-9a-f]{2}$`
 	cardRE       = `^card[0-9]+$`
 	renderdRE    = `^renderD[0-9]+$`
 )
 
 /* detect devices from sysfs drm directory (card id and renderD id) */
-func enumerateAllPossibleDevices() map[string]*DeviceInfo {
+func enumerateAllPossibleDevices(sysfsRoot string) map[string]*DeviceInfo {
+	devices, err := enumerateDrmDevices(sysfsRoot)
+	if err != nil {
+		klog.V(5).Infof("%v, resorting to deviceless / fake devices with environment variables only.", err)
+		return fakeDevices()
+	}
+	return devices
+}
+
+// Detect devices from sysfs drm directory without falling back to fake devices
+func enumerateDrmDevices(sysfsRoot string) (map[string]*DeviceInfo, error) {
 
 	cardRegexp := regexp.MustCompile(cardRE)
 	renderdRegexp := regexp.MustCompile(renderdRE)
-	drmFiles, err := os.ReadDir(sysfsDrmDir)
+	drmDir := filepath.Join(sysfsRoot, sysfsDrmDir)
+	drmFiles, err := os.ReadDir(drmDir)
 
 	if err != nil {
-		if err == os.ErrNotExist {
-			klog.V(5).Infof("No DRM Mydevice devices found on this host. %v does not exist.", sysfsDrmDir)
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no DRM Mydevice devices found on this host, %v does not exist", drmDir)
 		}
-		klog.V(5).Infof("Resorting to deviceless / fake devices with environment variables only.")
-		return fakeDevices()
+		return nil, fmt.Errorf("could not read DRM dir '%v': %v", drmDir, err)
 	}
 
-	klog.V(5).Infof("Found %d files in %v dir", len(drmFiles), sysfsDrmDir)
+	klog.V(5).Infof("Found %d files in %v dir", len(drmFiles), drmDir)
 
 	devices := make(map[string]*DeviceInfo)
 
@@ -62,18 +74,16 @@ func enumerateAllPossibleDevices() map[string]*DeviceInfo {
 		}
 		klog.V(5).Infof("Found DRM card device: " + drmFile.Name())
 
-		symlinkFile := filepath.Join(sysfsDrmDir, drmFile.Name())
+		symlinkFile := filepath.Join(drmDir, drmFile.Name())
 		pciDevDrmCard, err := os.Readlink(symlinkFile)
 		if err != nil {
-			klog.V(5).Infof("Could not read device DRM symlink '%v', resorting to fake devices", symlinkFile)
-			return fakeDevices()
+			return nil, fmt.Errorf("could not read device DRM symlink '%v': %v", symlinkFile, err)
 		}
 
-		drmDevDir := path.Join(sysfsDrmDir, pciDevDrmCard, "../")
+		drmDevDir := path.Join(drmDir, pciDevDrmCard, "../")
 		drmDevFiles, err := os.ReadDir(drmDevDir)
 		if err != nil {
-			klog.V(5).Infof("Could not read device DRM dir '%v', resorting to fake devices", drmDevDir)
-			return fakeDevices()
+			return nil, fmt.Errorf("could not read device DRM dir '%v': %v", drmDevDir, err)
 		}
 
 		cardDev := ""
@@ -118,6 +128,9 @@ func enumerateAllPossibleDevices() map[string]*DeviceInfo {
 		pciDBDF := filepath.Base(path.Join(drmDevDir, "../"))
 		klog.V(5).Infof("Discovered device is on PCI address %v", pciDBDF)
 
+		topology := readDeviceTopology(path.Join(drmDevDir, "../"))
+		klog.V(5).Infof("Discovered device topology: %+v", topology)
+
 		uid := fmt.Sprintf("%v-%v-%v", pciDBDF, vendor_id, device_id)
 		klog.V(5).Infof("New Mydevice UID: %v", uid)
 
@@ -127,13 +140,51 @@ func enumerateAllPossibleDevices() map[string]*DeviceInfo {
 			card:       cardDev,
 			renderd:    renderdDev,
 			deviceType: mycrd.MydeviceType0,
+			vendorId:   vendor_id,
+			deviceId:   device_id,
+			pciAddress: pciDBDF,
+			topology:   topology,
+			health:     mycrd.MydeviceHealthy,
 		}
 		klog.V(5).Infof("cdiname: %v", newDeviceInfo.cdiname)
 
 		devices[newDeviceInfo.uid] = newDeviceInfo
 
 	}
-	return devices
+	return devices, nil
+}
+
+// Read NUMA node, PCI root complex and upstream bridge of the PCI device.
+// pciDevDir is the sysfs device path, e.g. /sys/devices/pci0000:00/0000:00:01.0/0000:01:00.0
+func readDeviceTopology(pciDevDir string) *mycrd.MydeviceTopology {
+	topology := &mycrd.MydeviceTopology{
+		NUMANode: -1,
+	}
+
+	numaNodeFile := path.Join(pciDevDir, "numa_node")
+	numaNodeBytes, err := os.ReadFile(numaNodeFile)
+	if err != nil {
+		klog.V(5).Infof("Could not read NUMA node file (%s): %v", numaNodeFile, err)
+	} else if numaNode, err := strconv.Atoi(strings.TrimSpace(string(numaNodeBytes))); err == nil {
+		topology.NUMANode = numaNode
+	}
+
+	pciAddressRegexp := regexp.MustCompile(pciAddressRE)
+	parentDir := path.Dir(pciDevDir)
+	if pciAddressRegexp.MatchString(filepath.Base(parentDir)) {
+		topology.ParentBridge = filepath.Base(parentDir)
+	}
+
+	// only a root complex counts, not a directory of the sysfs root path that starts alike
+	pciRootRegexp := regexp.MustCompile(pciRootRE)
+	for _, dir := range strings.Split(pciDevDir, "/") {
+		if pciRootRegexp.MatchString(dir) {
+			topology.PCIRoot = dir
+			break
+		}
+	}
+
+	return topology
 }
 
 // Generate five fake devices
@@ -150,6 +201,7 @@ func fakeDevices() map[string]*DeviceInfo {
 			deviceType: mycrd.MydeviceType0,
 			card:       "",
 			renderd:    "",
+			health:     mycrd.MydeviceHealthy,
 		}
 		devices[newDeviceInfo.uid] = newDeviceInfo
 	}
@@ -0,0 +1,289 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+// fakePCIDevice describes a DRM device in a fake sysfs tree
+type fakePCIDevice struct {
+	root     string // PCI root complex, e.g. pci0000:00
+	bridge   string // upstream bridge, empty if the device sits on the root complex
+	address  string
+	numaNode string // no numa_node file if empty
+	card     string
+	renderd  string // no render node if empty
+	driver   bool
+	enable   string
+}
+
+func (d fakePCIDevice) dir(sysfs string) string {
+	return filepath.Join(sysfs, "devices", d.root, d.bridge, d.address)
+}
+
+func writeFile(t *testing.T, name string, content string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mkdirAll(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// relative symlink like the kernel creates them
+func symlink(t *testing.T, target, name string) {
+	t.Helper()
+	rel, err := filepath.Rel(filepath.Dir(name), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(rel, name); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Build a sysfs tree with the devices, as hack/create_fake_sysfs.sh does.
+// Returns the sysfs root.
+func createFakeSysfs(t *testing.T, devices ...fakePCIDevice) string {
+	t.Helper()
+	return createFakeSysfsAt(t, t.TempDir(), devices...)
+}
+
+func createFakeSysfsAt(t *testing.T, sysfs string, devices ...fakePCIDevice) string {
+	t.Helper()
+	drmDir := filepath.Join(sysfs, sysfsDrmDir)
+	pciDevicesDir := filepath.Join(sysfs, sysfsPCIDevicesDir)
+	driverDir := filepath.Join(sysfs, "bus/pci/drivers/i915")
+	mkdirAll(t, drmDir)
+	mkdirAll(t, pciDevicesDir)
+	mkdirAll(t, driverDir)
+	writeFile(t, filepath.Join(drmDir, "version"), "drm 1.1.0 20060810\n")
+
+	for _, device := range devices {
+		devDir := device.dir(sysfs)
+		mkdirAll(t, filepath.Join(devDir, "drm", device.card))
+		writeFile(t, filepath.Join(devDir, "vendor"), "0x8086\n")
+		writeFile(t, filepath.Join(devDir, "device"), "0x56a0\n")
+		writeFile(t, filepath.Join(devDir, "enable"), device.enable+"\n")
+		if device.numaNode != "" {
+			writeFile(t, filepath.Join(devDir, "numa_node"), device.numaNode+"\n")
+		}
+		if device.driver {
+			symlink(t, driverDir, filepath.Join(devDir, "driver"))
+		}
+		symlink(t, devDir, filepath.Join(pciDevicesDir, device.address))
+
+		symlink(t, filepath.Join(devDir, "drm", device.card), filepath.Join(drmDir, device.card))
+		// connectors show up in the drm class too and are no devices of their own
+		connector := device.card + "-DP-1"
+		mkdirAll(t, filepath.Join(devDir, "drm", device.card, connector))
+		symlink(t, filepath.Join(devDir, "drm", device.card, connector), filepath.Join(drmDir, connector))
+		if device.renderd != "" {
+			mkdirAll(t, filepath.Join(devDir, "drm", device.renderd))
+			symlink(t, filepath.Join(devDir, "drm", device.renderd), filepath.Join(drmDir, device.renderd))
+		}
+	}
+	return sysfs
+}
+
+var (
+	bridgedDevice = fakePCIDevice{
+		root:     "pci0000:00",
+		bridge:   "0000:00:01.0",
+		address:  "0000:01:00.0",
+		numaNode: "0",
+		card:     "card0",
+		renderd:  "renderD128",
+		driver:   true,
+		enable:   "1",
+	}
+	rootDevice = fakePCIDevice{
+		root:     "pci0000:80",
+		address:  "0000:80:02.0",
+		numaNode: "1",
+		card:     "card1",
+		driver:   true,
+		enable:   "1",
+	}
+)
+
+func TestEnumerateDrmDevices(t *testing.T) {
+	sysfs := createFakeSysfs(t, bridgedDevice, rootDevice)
+
+	devices, err := enumerateDrmDevices(sysfs)
+	if err != nil {
+		t.Fatalf("enumerateDrmDevices: %v", err)
+	}
+	if len(devices) != 2 {
+		t.Fatalf("expected 2 devices, got %d: %v", len(devices), devices)
+	}
+
+	expected := []struct {
+		uid        string
+		pciAddress string
+		card       string
+		renderd    string
+		topology   mycrd.MydeviceTopology
+	}{
+		{
+			uid:        "0000:01:00.0-0x8086-0x56a0",
+			pciAddress: "0000:01:00.0",
+			card:       "card0",
+			renderd:    "renderD128",
+			topology:   mycrd.MydeviceTopology{NUMANode: 0, PCIRoot: "pci0000:00", ParentBridge: "0000:00:01.0"},
+		},
+		{
+			uid:        "0000:80:02.0-0x8086-0x56a0",
+			pciAddress: "0000:80:02.0",
+			card:       "card1",
+			topology:   mycrd.MydeviceTopology{NUMANode: 1, PCIRoot: "pci0000:80"},
+		},
+	}
+	for _, e := range expected {
+		device, found := devices[e.uid]
+		if !found {
+			t.Errorf("device %v not found in %v", e.uid, devices)
+			continue
+		}
+		if device.cdiname != e.uid || device.pciAddress != e.pciAddress || device.vendorId != "0x8086" || device.deviceId != "0x56a0" {
+			t.Errorf("device %v: unexpected identity %+v", e.uid, device)
+		}
+		if device.card != e.card || device.renderd != e.renderd {
+			t.Errorf("device %v: expected card / renderD %q / %q, got %q / %q", e.uid, e.card, e.renderd, device.card, device.renderd)
+		}
+		if device.topology == nil || *device.topology != e.topology {
+			t.Errorf("device %v: expected topology %+v, got %+v", e.uid, e.topology, device.topology)
+		}
+		if device.health != mycrd.MydeviceHealthy {
+			t.Errorf("device %v: expected to be healthy, got %v", e.uid, device.health)
+		}
+	}
+}
+
+func TestEnumerateDrmDevicesWithoutDrmDir(t *testing.T) {
+	if _, err := enumerateDrmDevices(t.TempDir()); err == nil {
+		t.Errorf("expected an error without DRM dir")
+	}
+}
+
+func TestReadDeviceTopology(t *testing.T) {
+	device := bridgedDevice
+	device.numaNode = ""
+
+	testCases := []struct {
+		name  string
+		sysfs string
+	}{
+		{"plain root", t.TempDir()},
+		{"root path with pci prefix", filepath.Join(t.TempDir(), "pci-sysfs")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sysfs := createFakeSysfsAt(t, tc.sysfs, device)
+
+			topology := readDeviceTopology(device.dir(sysfs))
+			expected := mycrd.MydeviceTopology{NUMANode: -1, PCIRoot: "pci0000:00", ParentBridge: "0000:00:01.0"}
+			if *topology != expected {
+				t.Errorf("expected topology %+v, got %+v", expected, *topology)
+			}
+		})
+	}
+}
+
+func TestDeviceHealth(t *testing.T) {
+	testCases := []struct {
+		name     string
+		device   fakePCIDevice
+		remove   bool
+		expected mycrd.MydeviceHealth
+	}{
+		{
+			name:     "healthy",
+			device:   bridgedDevice,
+			expected: mycrd.MydeviceHealthy,
+		},
+		{
+			name: "disabled",
+			device: func() fakePCIDevice {
+				device := bridgedDevice
+				device.enable = "0"
+				return device
+			}(),
+			expected: mycrd.MydeviceUnhealthy,
+		},
+		{
+			name: "no driver",
+			device: func() fakePCIDevice {
+				device := bridgedDevice
+				device.driver = false
+				return device
+			}(),
+			expected: mycrd.MydeviceUnhealthy,
+		},
+		{
+			name:     "removed",
+			device:   bridgedDevice,
+			remove:   true,
+			expected: mycrd.MydeviceUnhealthy,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sysfs := createFakeSysfs(t, tc.device)
+			devices, err := enumerateDrmDevices(sysfs)
+			if err != nil {
+				t.Fatalf("enumerateDrmDevices: %v", err)
+			}
+			device, found := devices["0000:01:00.0-0x8086-0x56a0"]
+			if !found {
+				t.Fatalf("device not found in %v", devices)
+			}
+			if tc.remove {
+				if err := os.Remove(filepath.Join(sysfs, sysfsPCIDevicesDir, tc.device.address)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			health, reason := deviceHealth(sysfs, device)
+			if health != tc.expected {
+				t.Errorf("expected %v, got %v (%v)", tc.expected, health, reason)
+			}
+			if (health == mycrd.MydeviceHealthy) != (reason == "") {
+				t.Errorf("reason %q does not match health %v", reason, health)
+			}
+		})
+	}
+
+	t.Run("fake device", func(t *testing.T) {
+		health, _ := deviceHealth(t.TempDir(), fakeDevices()["fakeDevice00"])
+		if health != mycrd.MydeviceHealthy {
+			t.Errorf("expected fake device to be healthy, got %v", health)
+		}
+	})
+}
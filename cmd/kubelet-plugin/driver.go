@@ -20,19 +20,24 @@ import (
 	"context"
 	"fmt"
 
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	drapbv1 "k8s.io/kubelet/pkg/apis/dra/v1alpha1"
 
+	myclientset "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/clientset/versioned"
 	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
 )
 
 type driver struct {
-	mas   *mycrd.MydeviceAllocationState
-	state *nodeState
+	masConfig *mycrd.MydeviceAllocationStateConfig
+	myclient  myclientset.Interface
+	state     *nodeState
+	recorder  record.EventRecorder
 }
 
-func NewDriver(config *config_t) (*driver, error) {
+func NewDriver(config *config_t, recorder record.EventRecorder) (*driver, error) {
 	mas := mycrd.NewMydeviceAllocationState(config.crdconfig, config.clientset.example)
 
 	klog.V(3).Info("Creating new MydeviceAllocationState")
@@ -42,7 +47,7 @@ func NewDriver(config *config_t) (*driver, error) {
 	}
 
 	klog.V(3).Info("Creating new DeviceState")
-	state, err := newNodeState(mas)
+	state, err := newNodeState(mas, config)
 	if err != nil {
 		return nil, err
 	}
@@ -60,28 +65,66 @@ func NewDriver(config *config_t) (*driver, error) {
 	}
 
 	d := &driver{
-		mas:   mas,
-		state: state,
+		masConfig: config.crdconfig,
+		myclient:  config.clientset.example,
+		state:     state,
+		recorder:  recorder,
 	}
 	klog.V(3).Info("Finished creating new driver")
 
 	return d, nil
 }
 
+// The gRPC handlers and the background loops run concurrently, each of them
+// works on its own MAS object fetched right before use
+func (d *driver) newMAS() *mycrd.MydeviceAllocationState {
+	return mycrd.NewMydeviceAllocationState(d.masConfig, d.myclient)
+}
+
+// Publish the allocatable devices as the node state has them, claim allocations
+// made by the controller are left alone
+func (d *driver) publishAllocatableDevices() error {
+	mas := d.newMAS()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		err := mas.Get()
+		if err != nil {
+			return err
+		}
+		return mas.Update(d.state.getUpdatedAllocatableSpec(&mas.Spec))
+	})
+}
+
 func (d *driver) NodePrepareResource(ctx context.Context, req *drapbv1.NodePrepareResourceRequest) (*drapbv1.NodePrepareResourceResponse, error) {
 	klog.V(5).Infof("NodePrepareResource is called: request: %+v", req)
+	claim := types.NamespacedName{Namespace: req.Namespace, Name: req.ClaimName}
+
+	// prefer devices passed by the controller, this avoids fetching the MAS
+	if req.ResourceHandle != "" {
+		cdinames, err := d.prepareFromResourceHandle(req.ClaimUid, req.ResourceHandle)
+		if err == nil {
+			cdinames, err = d.appendClaimDevice(req.ClaimUid, cdinames)
+		}
+		if err == nil {
+			d.state.setClaimName(req.ClaimUid, claim)
+			klog.V(3).Infof("Prepared devices for claim '%v' from resource handle: %s", req.ClaimUid, cdinames)
+			return &drapbv1.NodePrepareResourceResponse{CdiDevices: cdinames}, nil
+		}
+		klog.Warningf("Could not prepare claim '%v' from resource handle, falling back to MydeviceAllocationState: %v", req.ClaimUid, err)
+	}
 
 	var err error
 	var cdinames []string
+	mas := d.newMAS()
 	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		err = d.mas.Get()
+		err = mas.Get()
 		if err != nil {
 			return err
 		}
 		klog.V(5).Info("MAS get OK")
 
-		err = d.state.syncAllocatedDevicesFromMASSpec(&d.mas.Spec)
-		if err != nil {
+		// other claims with devices that are gone do not keep this one from being prepared
+		skipped := d.state.syncAllocatedDevicesFromMASSpec(&mas.Spec)
+		if err, found := skipped[req.ClaimUid]; found {
 			return err
 		}
 
@@ -94,15 +137,53 @@ func (d *driver) NodePrepareResource(ctx context.Context, req *drapbv1.NodePrepa
 		return nil, fmt.Errorf("error preparing resource: %v", err)
 	}
 
+	cdinames, err = d.appendClaimDevice(req.ClaimUid, cdinames)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing resource: %v", err)
+	}
+
+	d.state.setClaimName(req.ClaimUid, claim)
 	klog.V(3).Infof("Prepared devices for claim '%v': %s", req.ClaimUid, cdinames)
 	return &drapbv1.NodePrepareResourceResponse{CdiDevices: cdinames}, nil
 }
 
+func (d *driver) prepareFromResourceHandle(claimUid string, resourceHandle string) ([]string, error) {
+	handle, err := mycrd.DecodeResourceHandle(resourceHandle)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.state.syncAllocatedDevicesFromResourceHandle(claimUid, handle)
+	if err != nil {
+		return nil, err
+	}
+
+	cdinames := d.state.getAllocatedAsCDIDevices(claimUid)
+	if len(cdinames) != len(handle.Mydevices) {
+		return nil, fmt.Errorf("found %d of %d devices in CDI registry", len(cdinames), len(handle.Mydevices))
+	}
+	return cdinames, nil
+}
+
+// The claim device passes the allocated count to the container, which matters
+// for claims with a count range
+func (d *driver) appendClaimDevice(claimUid string, cdinames []string) ([]string, error) {
+	if len(cdinames) == 0 {
+		return cdinames, nil
+	}
+	claimDevice, err := d.state.writeClaimSpec(claimUid)
+	if err != nil {
+		return nil, err
+	}
+	return append(cdinames, claimDevice), nil
+}
+
 func (d *driver) NodeUnprepareResource(ctx context.Context, req *drapbv1.NodeUnprepareResourceRequest) (*drapbv1.NodeUnprepareResourceResponse, error) {
 	klog.V(3).Infof("NodeUnprepareResource is called: request: %+v", req)
 
+	mas := d.newMAS()
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		err := d.mas.Get()
+		err := mas.Get()
 		if err != nil {
 			return fmt.Errorf("error freeing devices for claim '%v': %v", req.ClaimUid, err)
 		}
@@ -112,7 +193,11 @@ func (d *driver) NodeUnprepareResource(ctx context.Context, req *drapbv1.NodeUnp
 			return fmt.Errorf("error freeing devices for claim '%v': %v", req.ClaimUid, err)
 		}
 
-		err = d.mas.Update(d.state.getUpdatedSpec(&d.mas.Spec))
+		// claims prepared from the resource handle are not in the local state, so only
+		// this claim is removed, allocations of other claims stay as the controller made them
+		spec := d.state.getUpdatedAllocatableSpec(&mas.Spec)
+		delete(spec.ResourceClaimAllocations, req.ClaimUid)
+		err = mas.Update(spec)
 		if err != nil {
 			return err
 		}
@@ -123,6 +208,11 @@ func (d *driver) NodeUnprepareResource(ctx context.Context, req *drapbv1.NodeUnp
 		return nil, fmt.Errorf("error unpreparing resource: %v", err)
 	}
 
+	err = d.state.removeClaimSpec(req.ClaimUid)
+	if err != nil {
+		return nil, fmt.Errorf("error unpreparing resource: %v", err)
+	}
+
 	klog.V(3).Infof("Freed devices for claim '%v'", req.ClaimUid)
 	return &drapbv1.NodeUnprepareResourceResponse{}, nil
 }
@@ -0,0 +1,295 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	drapbv1 "k8s.io/kubelet/pkg/apis/dra/v1alpha1"
+
+	myfake "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/clientset/versioned/fake"
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+const testNamespace = "dra-example-driver"
+
+var masResource = v1alpha.SchemeGroupVersion.WithResource("mydeviceallocationstates")
+
+// Driver for node1 whose MAS has the allocatable devices of the node state and
+// the given claims allocated. The fake clientset rejects updates of stale
+// objects like the API server does.
+func newTestDriver(t *testing.T, state *nodeState, allocations map[string][]string) (*driver, *myfake.Clientset) {
+	mas := &v1alpha.MydeviceAllocationState{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1", Namespace: testNamespace, ResourceVersion: "1"},
+		Spec: v1alpha.MydeviceAllocationStateSpec{
+			ResourceClaimAllocations: map[string]v1alpha.AllocatedMydevices{},
+		},
+		Status: mycrd.MydeviceAllocationStateStatusReady,
+	}
+	state.syncAllocatableDevicesToMASSpec(&mas.Spec)
+	// allocated devices need not be allocatable, they may have been removed
+	for claimUid, uids := range allocations {
+		for _, uid := range uids {
+			mas.Spec.ResourceClaimAllocations[claimUid] = append(mas.Spec.ResourceClaimAllocations[claimUid], v1alpha.AllocatedMydevice{
+				UID:        uid,
+				Type:       mycrd.MydeviceType0,
+				CDIDevice:  DeviceInfo{cdiname: uid}.CDIDevice(),
+				MaxSharers: 1,
+			})
+		}
+	}
+
+	client := myfake.NewSimpleClientset()
+	if err := client.Tracker().Create(masResource, mas, testNamespace); err != nil {
+		t.Fatal(err)
+	}
+	resourceVersion := 1
+	// reactors run under the lock of the fake clientset
+	client.PrependReactor("update", "mydeviceallocationstates", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		update := action.(k8stesting.UpdateAction).GetObject().(*v1alpha.MydeviceAllocationState).DeepCopy()
+		current, err := client.Tracker().Get(masResource, update.Namespace, update.Name)
+		if err != nil {
+			return true, nil, err
+		}
+		if current.(*v1alpha.MydeviceAllocationState).ResourceVersion != update.ResourceVersion {
+			return true, nil, apierrors.NewConflict(masResource.GroupResource(), update.Name, fmt.Errorf("stale resource version %v", update.ResourceVersion))
+		}
+		resourceVersion++
+		update.ResourceVersion = strconv.Itoa(resourceVersion)
+		return true, update, client.Tracker().Update(masResource, update, update.Namespace)
+	})
+
+	d := &driver{
+		masConfig: &mycrd.MydeviceAllocationStateConfig{Name: "node1", Namespace: testNamespace},
+		myclient:  client,
+		state:     state,
+	}
+	return d, client
+}
+
+// Write the allocatable devices of the node state to its CDI registry
+func announceTestDevices(t *testing.T, state *nodeState) {
+	if err := syncDetectedDevicesWithCdiRegistry(state.cdi, state.allocatable, state.devRoot); err != nil {
+		t.Fatalf("writing CDI spec: %v", err)
+	}
+	if err := state.cdi.Refresh(); err != nil {
+		t.Fatalf("refreshing CDI registry: %v", err)
+	}
+}
+
+// Devices detected by a poll of sysfs
+func detectTestDevices(uids ...string) map[string]*DeviceInfo {
+	detected := make(map[string]*DeviceInfo)
+	for _, uid := range uids {
+		detected[uid] = &DeviceInfo{uid: uid, cdiname: uid, deviceType: mycrd.MydeviceType0}
+	}
+	return detected
+}
+
+func getTestMAS(t *testing.T, client *myfake.Clientset) *v1alpha.MydeviceAllocationState {
+	mas, err := client.DraV1alpha().MydeviceAllocationStates(testNamespace).Get(context.TODO(), "node1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mas
+}
+
+// Health checks and hotplug detection publish devices while the kubelet
+// unprepares claims, none of them may lose the others' MAS changes
+func TestConcurrentMASUpdates(t *testing.T) {
+	uids := []string{}
+	allocations := map[string][]string{}
+	for i := 0; i < 4; i++ {
+		uid := fmt.Sprintf("dev%d", i)
+		uids = append(uids, uid)
+		allocations[fmt.Sprintf("claim%d", i)] = []string{uid}
+	}
+	state := newTestNodeState(t, uids...)
+	for claimUid, devices := range allocations {
+		state.allocations[claimUid] = []*DeviceInfo{state.allocatable[devices[0]].DeepCopy()}
+	}
+	d, client := newTestDriver(t, state, allocations)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for claimUid := range allocations {
+		claimUid := claimUid
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := d.NodeUnprepareResource(context.TODO(), &drapbv1.NodeUnprepareResourceRequest{ClaimUid: claimUid})
+			errs <- err
+		}()
+	}
+	// health checks and hotplug detection
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				errs <- d.publishAllocatableDevices()
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	mas := getTestMAS(t, client)
+	if len(mas.Spec.ResourceClaimAllocations) != 0 {
+		t.Errorf("expected all claims unprepared, got %v", mas.Spec.ResourceClaimAllocations)
+	}
+	if len(mas.Spec.AllocatableMydevices) != len(uids) {
+		t.Errorf("expected %d allocatable devices, got %v", len(uids), mas.Spec.AllocatableMydevices)
+	}
+}
+
+func TestPrepareSkipsClaimsWithRemovedDevices(t *testing.T) {
+	state := newTestNodeState(t, "dev0", "dev1")
+	announceTestDevices(t, state)
+	d, _ := newTestDriver(t, state, map[string][]string{
+		"claim-a": {"dev0"},
+		"claim-b": {"dev1", "gone"},
+	})
+
+	_, err := d.NodePrepareResource(context.TODO(), &drapbv1.NodePrepareResourceRequest{ClaimUid: "claim-b"})
+	if err == nil {
+		t.Errorf("expected claim-b with a removed device to fail")
+	}
+
+	resp, err := d.NodePrepareResource(context.TODO(), &drapbv1.NodePrepareResourceRequest{ClaimUid: "claim-a"})
+	if err != nil {
+		t.Fatalf("preparing claim-a: %v", err)
+	}
+	expected := []string{cdiKind + "=dev0", cdiClaimKind + "=claim-a"}
+	if !reflect.DeepEqual(resp.CdiDevices, expected) {
+		t.Errorf("expected CDI devices %v, got %v", expected, resp.CdiDevices)
+	}
+
+	if _, found := state.allocations["claim-b"]; found {
+		t.Errorf("expected claim-b to be left out of the node state, got %v", state.allocations["claim-b"])
+	}
+}
+
+func TestRecordRemovedDevices(t *testing.T) {
+	state := newTestNodeState(t, "dev0", "dev1")
+	announceTestDevices(t, state)
+	d, _ := newTestDriver(t, state, map[string][]string{"claim-a": {"dev1"}})
+	recorder := record.NewFakeRecorder(10)
+	d.recorder = recorder
+
+	_, err := d.NodePrepareResource(context.TODO(), &drapbv1.NodePrepareResourceRequest{
+		Namespace: "default",
+		ClaimUid:  "claim-a",
+		ClaimName: "claim",
+	})
+	if err != nil {
+		t.Fatalf("preparing claim-a: %v", err)
+	}
+	// allocated before the plugin started and not prepared since
+	state.allocations["claim-b"] = []*DeviceInfo{state.allocatable["dev1"].DeepCopy()}
+
+	removed, err := state.rediscover(t.TempDir(), detectTestDevices("dev0"), 1)
+	if err != nil {
+		t.Fatalf("rediscover: %v", err)
+	}
+	expectedRemoved := map[string][]string{"claim-a": {"dev1"}, "claim-b": {"dev1"}}
+	if !reflect.DeepEqual(removed, expectedRemoved) {
+		t.Errorf("expected removed devices %v, got %v", expectedRemoved, removed)
+	}
+
+	d.recordRemovedDevices(removed)
+	close(recorder.Events)
+	events := []string{}
+	for event := range recorder.Events {
+		events = append(events, event)
+	}
+	expectedEvents := []string{"Warning DeviceRemoved Devices removed from node node1: dev1"}
+	if !reflect.DeepEqual(events, expectedEvents) {
+		t.Errorf("expected events %v, got %v", expectedEvents, events)
+	}
+}
+
+// Run with -race: hotplug detection changes the node state while the kubelet
+// prepares claims
+func TestRediscoverDuringPrepare(t *testing.T) {
+	state := newTestNodeState(t, "dev0", "dev1", "dev2", "dev3")
+	announceTestDevices(t, state)
+	d, _ := newTestDriver(t, state, map[string][]string{
+		"claim0": {"dev0"},
+		"claim1": {"dev1"},
+		"claim2": {"dev2"},
+		"claim3": {"dev3"},
+	})
+	sysfsRoot := t.TempDir()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// dev3 comes and goes
+		for i := 0; i < 10; i++ {
+			uids := []string{"dev0", "dev1", "dev2"}
+			if i%2 == 1 {
+				uids = append(uids, "dev3")
+			}
+			if _, err := state.rediscover(sysfsRoot, detectTestDevices(uids...), 1); err != nil {
+				errs <- fmt.Errorf("rediscover: %v", err)
+			}
+		}
+	}()
+	for _, claimUid := range []string{"claim0", "claim1", "claim2", "claim3"} {
+		claimUid := claimUid
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				resp, err := d.NodePrepareResource(context.TODO(), &drapbv1.NodePrepareResourceRequest{ClaimUid: claimUid})
+				// the device of claim3 may be gone, the others must not be affected by it
+				if claimUid == "claim3" {
+					continue
+				}
+				if err != nil {
+					errs <- fmt.Errorf("preparing %v: %v", claimUid, err)
+				} else if len(resp.CdiDevices) != 2 {
+					errs <- fmt.Errorf("expected a device and the claim device for %v, got %v", claimUid, resp.CdiDevices)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+const sysfsPCIDevicesDir = "bus/pci/devices"
+
+// Read the health of a PCI device from sysfs: the device must still exist, have
+// a driver bound and be enabled. Fake devices have no PCI address and are always healthy.
+// Returns the reason if the device is unhealthy.
+func deviceHealth(sysfsRoot string, device *DeviceInfo) (mycrd.MydeviceHealth, string) {
+	if device.pciAddress == "" {
+		return mycrd.MydeviceHealthy, ""
+	}
+
+	pciDevDir := filepath.Join(sysfsRoot, sysfsPCIDevicesDir, device.pciAddress)
+	if _, err := os.Stat(pciDevDir); err != nil {
+		return mycrd.MydeviceUnhealthy, fmt.Sprintf("PCI device not found: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(pciDevDir, "driver")); err != nil {
+		return mycrd.MydeviceUnhealthy, fmt.Sprintf("no driver bound: %v", err)
+	}
+
+	enableFile := filepath.Join(pciDevDir, "enable")
+	enable, err := os.ReadFile(enableFile)
+	if err != nil {
+		return mycrd.MydeviceUnhealthy, fmt.Sprintf("failed reading %v: %v", enableFile, err)
+	}
+	if strings.TrimSpace(string(enable)) == "0" {
+		return mycrd.MydeviceUnhealthy, "device is disabled"
+	}
+
+	return mycrd.MydeviceHealthy, ""
+}
+
+// Update the health of all allocatable devices, returns true if any changed
+func (s *nodeState) checkHealth(sysfsRoot string) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	changed := false
+	for _, device := range s.allocatable {
+		health, reason := deviceHealth(sysfsRoot, device)
+		if health == device.health {
+			continue
+		}
+
+		if health == mycrd.MydeviceUnhealthy {
+			klog.Warningf("Device %v is unhealthy: %v", device.uid, reason)
+		} else {
+			klog.Infof("Device %v is healthy again", device.uid)
+		}
+		device.health = health
+		changed = true
+	}
+	return changed
+}
+
+// Periodically check device health and publish changes in the MAS, the
+// controller does not allocate unhealthy devices
+func (d *driver) runHealthChecks(ctx context.Context, sysfsRoot string, interval time.Duration) {
+	publish := false
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if d.state.checkHealth(sysfsRoot) {
+			publish = true
+		}
+		if !publish {
+			return
+		}
+
+		err := d.publishAllocatableDevices()
+		if err != nil {
+			klog.Errorf("Error publishing device health: %v", err)
+			return
+		}
+		publish = false
+	}, interval)
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	resourcev1alpha1 "k8s.io/api/resource/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+const eventReasonDeviceRemoved = "DeviceRemoved"
+
+// What identifies the detected devices and their DRM device files, used to tell
+// when devices come and go, or get other card or renderD files after a reset
+func drmDevicesSignature(devices map[string]*DeviceInfo) string {
+	signatures := make([]string, 0, len(devices))
+	for _, device := range devices {
+		signatures = append(signatures, fmt.Sprintf("%v/%v/%v/%v/%v/%v",
+			device.uid, device.pciAddress, device.card, device.renderd, device.vendorId, device.deviceId))
+	}
+	sort.Strings(signatures)
+	return strings.Join(signatures, ",")
+}
+
+// Compare detected devices with the known ones, announce devices that were
+// added, unannounce devices that were removed and point the CDI devices of
+// those with other DRM device files to the new ones. Returns the removed
+// devices held by claims, by claim UID.
+func (s *nodeState) rediscover(sysfsRoot string, detected map[string]*DeviceInfo, maxSharers int) (map[string][]string, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	added := DevicesInfo{}
+	changed := DevicesInfo{}
+	for duid, device := range detected {
+		known, exists := s.allocatable[duid]
+		if !exists {
+			device.maxSharers = maxSharers
+			device.health, _ = deviceHealth(sysfsRoot, device)
+			added[duid] = device
+			continue
+		}
+		if known.card != device.card || known.renderd != device.renderd {
+			updated := known.DeepCopy()
+			updated.card = device.card
+			updated.renderd = device.renderd
+			changed[duid] = updated
+		}
+	}
+
+	removed := []string{}
+	for duid := range s.allocatable {
+		if _, exists := detected[duid]; !exists {
+			removed = append(removed, duid)
+		}
+	}
+	sort.Strings(removed)
+
+	if len(added) > 0 {
+		klog.Infof("Found %d new devices", len(added))
+		err := s.announceNewDevices(added)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(changed) > 0 {
+		klog.Infof("DRM device files of %d devices changed", len(changed))
+		err := s.refreshDeviceNodes(changed)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	claims := make(map[string][]string)
+	for _, duid := range removed {
+		klog.Infof("Device %v was removed", duid)
+		err := s.unannounceDevices(duid)
+		if err != nil {
+			return nil, err
+		}
+
+		for claimUid, devices := range s.allocations {
+			for _, device := range devices {
+				if device.uid == duid {
+					claims[claimUid] = append(claims[claimUid], duid)
+					break
+				}
+			}
+		}
+	}
+
+	return claims, nil
+}
+
+// Poll sysfs for devices that were hot-added, removed or reset and publish them
+// in the MAS. sysfs does not support inotify.
+func (d *driver) runHotplugDetection(ctx context.Context, sysfsRoot string, maxSharers int, interval time.Duration) {
+	// empty so that the first poll catches devices that changed since startup discovery
+	signature := ""
+	publish := false
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		detected, err := enumerateDrmDevices(sysfsRoot)
+		if err != nil {
+			klog.V(5).Infof("Not checking for hotplugged devices: %v", err)
+			return
+		}
+
+		if current := drmDevicesSignature(detected); current != signature {
+			klog.V(5).Infof("DRM devices changed, comparing with known devices")
+			claims, err := d.state.rediscover(sysfsRoot, detected, maxSharers)
+			if err != nil {
+				// signature is left alone, so the next poll retries
+				klog.Errorf("Error rediscovering devices: %v", err)
+				return
+			}
+			signature = current
+			publish = true
+			d.recordRemovedDevices(claims)
+		}
+		if !publish {
+			return
+		}
+
+		err = d.publishAllocatableDevices()
+		if err != nil {
+			klog.Errorf("Error publishing hotplugged devices: %v", err)
+			return
+		}
+		publish = false
+	}, interval)
+}
+
+// Tell claims that devices allocated to them are gone
+func (d *driver) recordRemovedDevices(removed map[string][]string) {
+	if len(removed) == 0 {
+		return
+	}
+
+	for claimUid, devices := range removed {
+		// claims allocated before the plugin started and not prepared since are only known by UID
+		name, found := d.state.getClaimName(claimUid)
+		if !found {
+			klog.Warningf("Devices %v of claim %v were removed, claim not known", devices, claimUid)
+			continue
+		}
+		klog.Warningf("Devices %v of claim %v were removed", devices, name)
+		claim := &corev1.ObjectReference{
+			APIVersion: resourcev1alpha1.SchemeGroupVersion.String(),
+			Kind:       "ResourceClaim",
+			Namespace:  name.Namespace,
+			Name:       name.Name,
+			UID:        types.UID(claimUid),
+		}
+		d.recorder.Eventf(claim, corev1.EventTypeWarning, eventReasonDeviceRemoved,
+			"Devices removed from node %v: %v", d.masConfig.Name, strings.Join(devices, ", "))
+	}
+}
@@ -21,15 +21,21 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	coreclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
 
 	cliflag "k8s.io/component-base/cli/flag"
 	"k8s.io/component-base/featuregate"
@@ -51,10 +57,17 @@ const (
 	driverPluginPath       = "/var/lib/kubelet/plugins/" + mycrd.ApiGroupName
 	driverPluginSocketPath = driverPluginPath + "/plugin.sock"
 
-	cdiRoot    = "/etc/cdi"
-	cdiVendor  = "example.com"
-	cdiVersion = "0.3.0"
-	cdiKind    = cdiVendor + "/mydevice"
+	cdiRoot       = "/etc/cdi"
+	cdiVendor     = "example.com"
+	cdiVersion    = "0.3.0"
+	cdiClass      = "mydevice"
+	cdiKind       = cdiVendor + "/" + cdiClass
+	cdiClaimClass = "claim"
+	cdiClaimKind  = cdiVendor + "/" + cdiClaimClass
+
+	// hostPath of device nodes, needed for a dev root other than /dev, came with this version
+	cdiHostPathVersion = "0.5.0"
+	containerDriDir    = "/dev/dri"
 
 	kubeApiQps   = 5
 	kubeApiBurst = 10
@@ -65,9 +78,18 @@ type clientset_t struct {
 	example myclientset.Interface
 }
 
+type flags_t struct {
+	maxSharers          *int
+	sysfsRoot           *string
+	devRoot             *string
+	healthCheckInterval *time.Duration
+	hotplugInterval     *time.Duration
+}
+
 type config_t struct {
 	crdconfig *mycrd.MydeviceAllocationStateConfig
 	clientset *clientset_t
+	flags     *flags_t
 }
 
 func main() {
@@ -89,10 +111,20 @@ func newCommand() *cobra.Command {
 		Long:  "Example Mydevice resource-driver kubelet-plugin runs as a device plugin for kubelet that supports dynamic resource allocation.",
 	}
 
+	flags := &flags_t{}
+
 	sharedFlagSets := cliflag.NamedFlagSets{}
 	fs := sharedFlagSets.FlagSet("logging")
 	logsapi.AddFlags(logsconfig, fs)
 	logs.AddFlags(fs, logs.SkipLoggingConfigurationFlags())
+
+	fs = sharedFlagSets.FlagSet("devices")
+	flags.maxSharers = fs.Int("max-sharers", 0, "Maximum number of resource claims that can share one device, 1 means devices are exclusive. 0 leaves it to the resource class parameters, which make devices exclusive by default.")
+	flags.sysfsRoot = fs.String("sysfs-root", "/sys", "Root of the sysfs tree devices are discovered and health checked in, e.g. a fake tree for testing.")
+	flags.devRoot = fs.String("dev-root", "/dev", "Root of the host device tree the dri device nodes are passed to containers from, containers always get them in /dev/dri.")
+	flags.healthCheckInterval = fs.Duration("health-check-interval", 30*time.Second, "How often device health is read from sysfs, 0 disables health checks.")
+	flags.hotplugInterval = fs.Duration("hotplug-interval", 10*time.Second, "How often sysfs is polled for added and removed devices, 0 disables hotplug detection.")
+
 	fs = cmd.PersistentFlags()
 	for _, f := range sharedFlagSets.FlagSets {
 		fs.AddFlagSet(f)
@@ -108,6 +140,22 @@ func newCommand() *cobra.Command {
 			return err
 		}
 
+		if *flags.maxSharers < 0 {
+			return fmt.Errorf("max-sharers must not be negative, got %v", *flags.maxSharers)
+		}
+
+		if !filepath.IsAbs(*flags.devRoot) {
+			return fmt.Errorf("dev-root must be an absolute path, got %v", *flags.devRoot)
+		}
+
+		if *flags.healthCheckInterval < 0 {
+			return fmt.Errorf("health-check-interval must not be negative, got %v", *flags.healthCheckInterval)
+		}
+
+		if *flags.hotplugInterval < 0 {
+			return fmt.Errorf("hotplug-interval must not be negative, got %v", *flags.hotplugInterval)
+		}
+
 		return nil
 	}
 
@@ -157,6 +205,7 @@ func newCommand() *cobra.Command {
 				coreclient,
 				myclient,
 			},
+			flags: flags,
 		}
 
 		return CallPlugin(config)
@@ -199,11 +248,27 @@ func CallPlugin(config *config_t) error {
 		return err
 	}
 
-	driver, err := NewDriver(config)
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: config.clientset.core.CoreV1().Events("")})
+	defer eventBroadcaster.Shutdown()
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: mycrd.ApiGroupName + "-kubelet-plugin", Host: config.crdconfig.Name})
+
+	driver, err := NewDriver(config, recorder)
 	if err != nil {
 		return err
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if *config.flags.healthCheckInterval > 0 {
+		go driver.runHealthChecks(ctx, *config.flags.sysfsRoot, *config.flags.healthCheckInterval)
+	}
+
+	if *config.flags.hotplugInterval > 0 {
+		go driver.runHotplugDetection(ctx, *config.flags.sysfsRoot, *config.flags.maxSharers, *config.flags.hotplugInterval)
+	}
+
 	klog.Infof(`Starting DRA resource-driver kubelet-plugin
 RegistrarSocketPath: %v
 PluginSocketPath: %v
@@ -19,21 +19,29 @@ package main
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	cdiapi "github.com/container-orchestrated-devices/container-device-interface/pkg/cdi"
 	specs "github.com/container-orchestrated-devices/container-device-interface/specs-go"
 	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
 	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 )
 
 type DeviceInfo struct {
-	uid        string // Unique identifier, for instance PCI_DBDF-PCI_DEVICE_ID
-	cdiname    string // name field from cdi spec, uid if handled by this resource-driver
-	deviceType string // in case several different device types are supported
-	card       string // card DRM device file name, can be empty if devices are faked
-	renderd    string // renderd DRM device file name, can be empty
+	uid        string                  // Unique identifier, for instance PCI_DBDF-PCI_DEVICE_ID
+	cdiname    string                  // name field from cdi spec, uid if handled by this resource-driver
+	deviceType string                  // in case several different device types are supported
+	card       string                  // card DRM device file name, can be empty if devices are faked
+	renderd    string                  // renderd DRM device file name, can be empty
+	vendorId   string                  // PCI vendor ID, empty if devices are faked
+	deviceId   string                  // PCI device ID, empty if devices are faked
+	pciAddress string                  // PCI DBDF, empty if devices are faked
+	maxSharers int                     // claims that can share the device, or the limit the claim was allocated with
+	topology   *mycrd.MydeviceTopology // nil if devices are faked
+	health     mycrd.MydeviceHealth    // result of the last health check
 }
 
 func (g *DeviceInfo) DeepCopy() *DeviceInfo {
@@ -43,6 +51,12 @@ func (g *DeviceInfo) DeepCopy() *DeviceInfo {
 		deviceType: g.deviceType,
 		card:       g.card,
 		renderd:    g.renderd,
+		vendorId:   g.vendorId,
+		deviceId:   g.deviceId,
+		pciAddress: g.pciAddress,
+		maxSharers: g.maxSharers,
+		topology:   g.topology.DeepCopy(),
+		health:     g.health,
 	}
 }
 
@@ -61,21 +75,24 @@ type ClaimAllocations map[string][]*DeviceInfo
 type nodeState struct {
 	sync.Mutex
 	cdi         cdiapi.Registry
+	devRoot     string
 	allocatable map[string]*DeviceInfo
 	allocations ClaimAllocations
+	claims      map[string]types.NamespacedName // prepared claims by UID, the kubelet only names them in NodePrepareResource
 }
 
 func (g DeviceInfo) CDIDevice() string {
 	return fmt.Sprintf("%s=%s", cdiKind, g.cdiname)
 }
 
-func newNodeState(mas *mycrd.MydeviceAllocationState) (*nodeState, error) {
+func newNodeState(mas *mycrd.MydeviceAllocationState, config *config_t) (*nodeState, error) {
 	klog.V(3).Infof("Enumerating all devices")
-	detecteddevices := enumerateAllPossibleDevices()
+	detecteddevices := enumerateAllPossibleDevices(*config.flags.sysfsRoot)
 
 	klog.V(5).Infof("Detected %d devices", len(detecteddevices))
 
-	for ddev := range detecteddevices {
+	for ddev, device := range detecteddevices {
+		device.maxSharers = *config.flags.maxSharers
 		klog.V(3).Infof("new device: %+v", ddev)
 	}
 
@@ -91,7 +108,7 @@ func newNodeState(mas *mycrd.MydeviceAllocationState) (*nodeState, error) {
 	}
 
 	// syncDetectedDevicesWithCdiRegistry overrides uid in detecteddevices from existing cdi spec
-	err = syncDetectedDevicesWithCdiRegistry(cdi, detecteddevices)
+	err = syncDetectedDevicesWithCdiRegistry(cdi, detecteddevices, *config.flags.devRoot)
 	if err != nil {
 		return nil, fmt.Errorf("unable to sync detected devices to CDI registry: %v", err)
 	}
@@ -108,15 +125,16 @@ func newNodeState(mas *mycrd.MydeviceAllocationState) (*nodeState, error) {
 	// TODO: allocatable should include cdi-described
 	state := &nodeState{
 		cdi:         cdi,
+		devRoot:     *config.flags.devRoot,
 		allocatable: detecteddevices,
 		allocations: make(ClaimAllocations),
 	}
 
+	state.checkHealth(*config.flags.sysfsRoot)
+
 	klog.V(5).Infof("Syncing allocatable devices")
-	err = state.syncAllocatedDevicesFromMASSpec(&mas.Spec)
-	if err != nil {
-		return nil, fmt.Errorf("unable to sync allocated devices from CRD: %v", err)
-	}
+	// claims with devices that are gone fail when they are prepared
+	state.syncAllocatedDevicesFromMASSpec(&mas.Spec)
 	klog.V(5).Infof("Synced state with CDI and CRD: %+v", state)
 	for duid, ddev := range state.allocatable {
 		klog.V(5).Infof("Allocatable device: %v : %+v", duid, ddev)
@@ -128,7 +146,7 @@ func newNodeState(mas *mycrd.MydeviceAllocationState) (*nodeState, error) {
 // Add detected devices into cdi registry if they are not yet there.
 // Update existing registry devices with detected.
 // Remove absent registry devices
-func syncDetectedDevicesWithCdiRegistry(registry cdiapi.Registry, detectedDevices DevicesInfo) error {
+func syncDetectedDevicesWithCdiRegistry(registry cdiapi.Registry, detectedDevices DevicesInfo, devRoot string) error {
 
 	vendorSpecs := registry.SpecDB().GetVendorSpecs(cdiVendor)
 	devicesToAdd := detectedDevices.DeepCopy()
@@ -140,6 +158,10 @@ func syncDetectedDevicesWithCdiRegistry(registry cdiapi.Registry, detectedDevice
 		// - write spec
 		// add rest of detected devices to first vendor spec
 		for specidx, vendorSpec := range vendorSpecs {
+			// per-claim specs are managed by writeClaimSpec
+			if vendorSpec.GetClass() != cdiClass {
+				continue
+			}
 			klog.V(5).Infof("checking vendorspec %v", specidx)
 
 			specChanged := false // if devices were updated or deleted
@@ -173,9 +195,19 @@ func syncDetectedDevicesWithCdiRegistry(registry cdiapi.Registry, detectedDevice
 
 		if len(devicesToAdd) > 0 {
 			// add devices that were not found in registry to the first existing vendor spec
-			apispec := vendorSpecs[0]
+			var apispec *cdiapi.Spec
+			for _, vendorSpec := range vendorSpecs {
+				if vendorSpec.GetClass() == cdiClass {
+					apispec = vendorSpec
+					break
+				}
+			}
+			if apispec == nil {
+				klog.V(5).Info("Creating new CDI spec for detected devices")
+				return addNewDevicesToNewRegistry(devicesToAdd, devRoot)
+			}
 			klog.V(5).Infof("Adding %d devices to CDI spec", len(devicesToAdd))
-			addDevicesToCDISpec(devicesToAdd, apispec.Spec)
+			addDevicesToCDISpec(devicesToAdd, apispec.Spec, devRoot)
 			specName := filepath.Base(apispec.GetPath())
 			klog.V(5).Infof("Overwriting spec %v", specName)
 			err := registry.SpecDB().WriteSpec(apispec.Spec, specName)
@@ -186,7 +218,7 @@ func syncDetectedDevicesWithCdiRegistry(registry cdiapi.Registry, detectedDevice
 		}
 	} else {
 		klog.V(5).Info("Creating new CDI spec for detected devices")
-		if err := addNewDevicesToNewRegistry(devicesToAdd); err != nil {
+		if err := addNewDevicesToNewRegistry(devicesToAdd, devRoot); err != nil {
 			klog.V(5).Infof("Failed adding devices to new CDI registry: %v", err)
 			return err
 		}
@@ -195,19 +227,18 @@ func syncDetectedDevicesWithCdiRegistry(registry cdiapi.Registry, detectedDevice
 	return nil
 }
 
-func addDevicesToCDISpec(devices DevicesInfo, spec *specs.Spec) {
+// Device nodes are taken from the dri dir under devRoot on the host,
+// containers always get them in /dev/dri
+func addDevicesToCDISpec(devices DevicesInfo, spec *specs.Spec, devRoot string) {
+	if filepath.Join(devRoot, "dri") != containerDriDir && spec.Version == cdiVersion {
+		spec.Version = cdiHostPathVersion
+	}
+
 	for _, device := range devices {
-		deviceNodes := []*specs.DeviceNode{}
-		if device.card != "" {
-			deviceNodes = append(deviceNodes, &specs.DeviceNode{Path: fmt.Sprintf("/dev/dri/%s", device.card), Type: "c"})
-		}
-		if device.renderd != "" {
-			deviceNodes = append(deviceNodes, &specs.DeviceNode{Path: fmt.Sprintf("/dev/dri/%s", device.renderd), Type: "c"})
-		}
 		newDevice := specs.Device{
 			Name: device.cdiname,
 			ContainerEdits: specs.ContainerEdits{
-				DeviceNodes: deviceNodes,
+				DeviceNodes: driDeviceNodes(devRoot, device),
 				Env:         []string{"ENV_A=value1", "ENV_B=value2"},
 			},
 		}
@@ -216,8 +247,30 @@ func addDevicesToCDISpec(devices DevicesInfo, spec *specs.Spec) {
 	}
 }
 
+func driDeviceNodes(devRoot string, device *DeviceInfo) []*specs.DeviceNode {
+	deviceNodes := []*specs.DeviceNode{}
+	if device.card != "" {
+		deviceNodes = append(deviceNodes, driDeviceNode(devRoot, device.card))
+	}
+	if device.renderd != "" {
+		deviceNodes = append(deviceNodes, driDeviceNode(devRoot, device.renderd))
+	}
+	return deviceNodes
+}
+
+func driDeviceNode(devRoot string, name string) *specs.DeviceNode {
+	deviceNode := &specs.DeviceNode{
+		Path: filepath.Join(containerDriDir, name),
+		Type: "c",
+	}
+	if hostPath := filepath.Join(devRoot, "dri", name); hostPath != deviceNode.Path {
+		deviceNode.HostPath = hostPath
+	}
+	return deviceNode
+}
+
 // Write devices into new vendor-specific CDI spec, should only be called if such spec does not exist
-func addNewDevicesToNewRegistry(devices DevicesInfo) error {
+func addNewDevicesToNewRegistry(devices DevicesInfo, devRoot string) error {
 	klog.V(5).Infof("Adding %v devices to new spec", len(devices))
 	registry := cdiapi.GetRegistry()
 	spec := &specs.Spec{
@@ -225,7 +278,7 @@ func addNewDevicesToNewRegistry(devices DevicesInfo) error {
 		Kind:    cdiKind,
 	}
 
-	addDevicesToCDISpec(devices, spec)
+	addDevicesToCDISpec(devices, spec, devRoot)
 	klog.V(5).Infof("spec devices length: %v", len(spec.Devices))
 
 	specname, err := cdiapi.GenerateNameForSpec(spec)
@@ -240,6 +293,7 @@ func (s *nodeState) free(claimUid string) error {
 	s.Lock()
 	defer s.Unlock()
 
+	delete(s.claims, claimUid)
 	if s.allocations[claimUid] == nil {
 		return nil
 	}
@@ -272,7 +326,39 @@ func (s *nodeState) getUpdatedSpec(inspec *mycrd.MydeviceAllocationStateSpec) *m
 	return outspec
 }
 
+// Like getUpdatedSpec, but leaves claim allocations made by the controller alone
+func (s *nodeState) getUpdatedAllocatableSpec(inspec *mycrd.MydeviceAllocationStateSpec) *mycrd.MydeviceAllocationStateSpec {
+	s.Lock()
+	defer s.Unlock()
+
+	outspec := inspec.DeepCopy()
+	s.syncAllocatableDevicesToMASSpec(outspec)
+	return outspec
+}
+
+// Remember namespace and name of a prepared claim, to record events on it
+func (s *nodeState) setClaimName(claimUid string, claim types.NamespacedName) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.claims == nil {
+		s.claims = make(map[string]types.NamespacedName)
+	}
+	s.claims[claimUid] = claim
+}
+
+func (s *nodeState) getClaimName(claimUid string) (types.NamespacedName, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	claim, found := s.claims[claimUid]
+	return claim, found
+}
+
 func (s *nodeState) getAllocatedAsCDIDevices(claimUid string) []string {
+	s.Lock()
+	defer s.Unlock()
+
 	var devs []string
 	klog.V(5).Infof("getAllocatedAsCDIDevices is called")
 	for _, device := range s.allocations[claimUid] {
@@ -287,28 +373,85 @@ func (s *nodeState) getAllocatedAsCDIDevices(claimUid string) []string {
 	return devs
 }
 
+// Write a transient CDI spec with a single device named after the claim that
+// tells the container how many devices it got and which ones
+func (s *nodeState) writeClaimSpec(claimUid string) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	uids := []string{}
+	for _, device := range s.allocations[claimUid] {
+		uids = append(uids, device.uid)
+	}
+
+	spec := &specs.Spec{
+		Version: cdiVersion,
+		Kind:    cdiClaimKind,
+		Devices: []specs.Device{
+			{
+				Name: claimUid,
+				ContainerEdits: specs.ContainerEdits{
+					Env: []string{
+						fmt.Sprintf("MYDEVICE_COUNT=%d", len(uids)),
+						fmt.Sprintf("MYDEVICE_UIDS=%s", strings.Join(uids, ",")),
+					},
+				},
+			},
+		},
+	}
+
+	specName := cdiapi.GenerateTransientSpecName(cdiVendor, cdiClaimClass, claimUid)
+	klog.V(5).Infof("Writing claim spec %v", specName)
+	err := s.cdi.SpecDB().WriteSpec(spec, specName)
+	if err != nil {
+		return "", fmt.Errorf("failed writing CDI spec for claim %v: %v", claimUid, err)
+	}
+	return cdiapi.QualifiedName(cdiVendor, cdiClaimClass, claimUid), nil
+}
+
+func (s *nodeState) removeClaimSpec(claimUid string) error {
+	specName := cdiapi.GenerateTransientSpecName(cdiVendor, cdiClaimClass, claimUid)
+	klog.V(5).Infof("Removing claim spec %v", specName)
+	return s.cdi.SpecDB().RemoveSpec(specName)
+}
+
+// Callers hold the lock, like for syncAllocatedDevicesToMASSpec
 func (s *nodeState) syncAllocatableDevicesToMASSpec(spec *mycrd.MydeviceAllocationStateSpec) {
 	devices := make(map[string]mycrd.AllocatableMydevice)
 	for _, device := range s.allocatable {
 		devices[device.uid] = mycrd.AllocatableMydevice{
-			CDIDevice: device.cdiname,
-			Type:      v1alpha.MydeviceType(device.deviceType),
-			UID:       device.uid,
+			CDIDevice:  device.cdiname,
+			Type:       v1alpha.MydeviceType(device.deviceType),
+			UID:        device.uid,
+			VendorID:   device.vendorId,
+			DeviceID:   device.deviceId,
+			PCIAddress: device.pciAddress,
+			MaxSharers: device.maxSharers,
+			Topology:   device.topology.DeepCopy(),
+			Health:     device.health,
+			// cordon is set by admins on the MAS, keep it
+			Cordoned: spec.AllocatableMydevices[device.uid].Cordoned,
 		}
 	}
 
 	spec.AllocatableMydevices = devices
 }
 
-func (s *nodeState) syncAllocatedDevicesFromMASSpec(spec *mycrd.MydeviceAllocationStateSpec) error {
+// Claims with allocated devices that are no longer available are left out of
+// the internal state, the others are synced. Returns why each claim was left out.
+func (s *nodeState) syncAllocatedDevicesFromMASSpec(spec *mycrd.MydeviceAllocationStateSpec) map[string]error {
+	s.Lock()
+	defer s.Unlock()
+
 	klog.V(5).Infof("Syncing %d resource claim allocations from MAS to internal state", len(spec.ResourceClaimAllocations))
 	if s.allocations == nil {
 		s.allocations = make(ClaimAllocations)
 	}
 
+	skipped := make(map[string]error)
 	for claimUid, devices := range spec.ResourceClaimAllocations {
 		klog.V(5).Infof("claim %v has %v devices", claimUid, len(devices))
-		s.allocations[claimUid] = []*DeviceInfo{}
+		allocated := []*DeviceInfo{}
 		for _, d := range devices {
 			klog.V(5).Infof("Device: %+v", d)
 			switch d.Type {
@@ -316,17 +459,50 @@ func (s *nodeState) syncAllocatedDevicesFromMASSpec(spec *mycrd.MydeviceAllocati
 				klog.V(5).Info("Matched MydeviceType0 type in sync")
 				if _, exists := s.allocatable[d.UID]; !exists {
 					klog.Errorf("Allocated device %v no longer available for claim %v", d.UID, claimUid)
-					// TODO: handle this better: wipe resource claim allocation if claimAllocation does not exist anymore
-					return fmt.Errorf("Could not find allocated device %v for claimAllocation %v", d.UID, claimUid)
+					skipped[claimUid] = fmt.Errorf("allocated device %v is no longer available", d.UID)
+					continue
 				}
 				newdevice := s.allocatable[d.UID].DeepCopy()
-				s.allocations[claimUid] = append(s.allocations[claimUid], newdevice)
+				newdevice.maxSharers = d.MaxSharers
+				allocated = append(allocated, newdevice)
 			default:
 				klog.Errorf("Unsupported device type: %v", d.Type)
 			}
 		}
+
+		if skipped[claimUid] != nil {
+			delete(s.allocations, claimUid)
+			continue
+		}
+		s.allocations[claimUid] = allocated
 	}
 
+	return skipped
+}
+
+func (s *nodeState) syncAllocatedDevicesFromResourceHandle(claimUid string, handle *mycrd.ResourceHandle) error {
+	s.Lock()
+	defer s.Unlock()
+
+	devices := []*DeviceInfo{}
+	for _, d := range handle.Mydevices {
+		switch d.Type {
+		case mycrd.MydeviceType0:
+			if _, exists := s.allocatable[d.UID]; !exists {
+				return fmt.Errorf("Could not find allocated device %v for claimAllocation %v", d.UID, claimUid)
+			}
+			newdevice := s.allocatable[d.UID].DeepCopy()
+			newdevice.maxSharers = d.MaxSharers
+			devices = append(devices, newdevice)
+		default:
+			return fmt.Errorf("unsupported device type: %v", d.Type)
+		}
+	}
+
+	if s.allocations == nil {
+		s.allocations = make(ClaimAllocations)
+	}
+	s.allocations[claimUid] = devices
 	return nil
 }
 
@@ -338,9 +514,10 @@ func (s *nodeState) syncAllocatedDevicesToMASSpec(masspec *mycrd.MydeviceAllocat
 			switch device.deviceType {
 			case mycrd.MydeviceType0:
 				outdevice := mycrd.AllocatedMydevice{
-					UID:       device.uid,
-					CDIDevice: device.CDIDevice(),
-					Type:      v1alpha.MydeviceType(device.deviceType),
+					UID:        device.uid,
+					CDIDevice:  device.CDIDevice(),
+					Type:       v1alpha.MydeviceType(device.deviceType),
+					MaxSharers: device.maxSharers,
 				}
 				allocatedDevices = append(allocatedDevices, outdevice)
 			default:
@@ -360,13 +537,28 @@ func (s *nodeState) announceNewDevices(newDevices DevicesInfo) error {
 		return fmt.Errorf("Unable to refresh the CDI registry: %v", err)
 	}
 
+	// the sync removes CDI devices it is not given, so pass the known devices too
+	devices := DevicesInfo{}
+	for duid, device := range s.allocatable {
+		devices[duid] = device
+	}
+	for duid, device := range newDevices {
+		devices[duid] = device
+	}
+
 	klog.V(5).Infof("Adding %v new devices to CDI", len(newDevices))
-	err = syncDetectedDevicesWithCdiRegistry(s.cdi, newDevices)
+	err = syncDetectedDevicesWithCdiRegistry(s.cdi, devices, s.devRoot)
 	if err != nil {
 		klog.Errorf("Failed announcing new devices: %v", err)
 		return fmt.Errorf("Failed announcing new devices: %v", err)
 	}
 
+	// NodePrepareResource looks the new devices up in the registry
+	err = s.cdi.Refresh()
+	if err != nil {
+		return fmt.Errorf("Unable to refresh the CDI registry after announcing new devices: %v", err)
+	}
+
 	// Adding new devices to s.allocatable is enough, getUpdatedSpec will be called in NodePrepareResource
 	for duid, device := range newDevices {
 		s.allocatable[duid] = device
@@ -375,6 +567,52 @@ func (s *nodeState) announceNewDevices(newDevices DevicesInfo) error {
 	return nil
 }
 
+// Point the CDI devices to the current DRM device files of the devices, which
+// change when a device is reset or the devices are enumerated again
+func (s *nodeState) refreshDeviceNodes(devices DevicesInfo) error {
+	klog.V(5).Infof("Refreshing CDI registry")
+	err := s.cdi.Refresh()
+	if err != nil {
+		return fmt.Errorf("Unable to refresh the CDI registry: %v", err)
+	}
+
+	for _, spec := range s.cdi.SpecDB().GetVendorSpecs(cdiVendor) {
+		if spec.GetClass() != cdiClass {
+			continue
+		}
+
+		specChanged := false
+		for idx, specDevice := range spec.Spec.Devices {
+			device, found := devices[specDevice.Name]
+			if !found {
+				continue
+			}
+			klog.V(5).Infof("Updating device nodes of device %v", specDevice.Name)
+			spec.Spec.Devices[idx].ContainerEdits.DeviceNodes = driDeviceNodes(s.devRoot, device)
+			specChanged = true
+		}
+		if specChanged {
+			specName := filepath.Base(spec.GetPath())
+			klog.V(5).Infof("Overwriting spec %v", specName)
+			err = s.cdi.SpecDB().WriteSpec(spec.Spec, specName)
+			if err != nil {
+				return fmt.Errorf("Failed writing CDI spec %v: %v", spec.GetPath(), err)
+			}
+		}
+	}
+
+	// NodePrepareResource looks the devices up in the registry
+	err = s.cdi.Refresh()
+	if err != nil {
+		return fmt.Errorf("Unable to refresh the CDI registry after updating device nodes: %v", err)
+	}
+
+	for duid, device := range devices {
+		s.allocatable[duid] = device
+	}
+	return nil
+}
+
 func (s *nodeState) unannounceDevices(deviceUid string) error {
 	klog.V(5).Infof("unannounceDevices called for parentUid: %v", deviceUid)
 	// MAS spec will beb updated with s.allocatable in NodeUnprepareResource call to getUpdatedSpec
@@ -392,6 +630,9 @@ func (s *nodeState) unannounceDevices(deviceUid string) error {
 	}
 
 	for _, spec := range s.cdi.SpecDB().GetVendorSpecs(cdiVendor) {
+		if spec.GetClass() != cdiClass {
+			continue
+		}
 		klog.V(5).Infof("Checking for devices in CDI spec: %+v", spec)
 
 		filteredDevices := []specs.Device{}
@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	cdiapi "github.com/container-orchestrated-devices/container-device-interface/pkg/cdi"
+
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+// Node state with a CDI registry in a temporary directory and the given devices
+// allocatable. The registry is refreshed explicitly, not by watching the directory.
+func newTestNodeState(t *testing.T, uids ...string) *nodeState {
+	cdiDir := t.TempDir()
+	registry := cdiapi.GetRegistry(cdiapi.WithSpecDirs(cdiDir), cdiapi.WithAutoRefresh(false))
+	if err := registry.Refresh(); err != nil {
+		t.Fatalf("refreshing CDI registry: %v", err)
+	}
+
+	state := &nodeState{
+		cdi:         registry,
+		allocatable: map[string]*DeviceInfo{},
+		allocations: ClaimAllocations{},
+	}
+	for _, uid := range uids {
+		state.allocatable[uid] = &DeviceInfo{
+			uid:        uid,
+			cdiname:    uid,
+			deviceType: mycrd.MydeviceType0,
+			maxSharers: 1,
+		}
+	}
+	return state
+}
+
+func TestClaimSpec(t *testing.T) {
+	state := newTestNodeState(t, "dev0", "dev1", "dev2")
+	cdiDir := state.cdi.GetSpecDirectories()[0]
+	state.allocations["claim-uid"] = []*DeviceInfo{state.allocatable["dev0"], state.allocatable["dev2"]}
+
+	name, err := state.writeClaimSpec("claim-uid")
+	if err != nil {
+		t.Fatalf("writeClaimSpec: %v", err)
+	}
+	if expected := cdiClaimKind + "=claim-uid"; name != expected {
+		t.Errorf("expected CDI device %v, got %v", expected, name)
+	}
+
+	specFiles, err := filepath.Glob(filepath.Join(cdiDir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(specFiles) != 1 {
+		t.Fatalf("expected a single claim spec file, got %v", specFiles)
+	}
+
+	if err := state.cdi.Refresh(); err != nil {
+		t.Fatalf("refreshing CDI registry: %v", err)
+	}
+	device := state.cdi.DeviceDB().GetDevice(name)
+	if device == nil {
+		t.Fatalf("CDI device %v not found in registry: %v", name, state.cdi.GetErrors())
+	}
+	env := append([]string{}, device.ContainerEdits.Env...)
+	sort.Strings(env)
+	if expected := []string{"MYDEVICE_COUNT=2", "MYDEVICE_UIDS=dev0,dev2"}; !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected container environment %v, got %v", expected, env)
+	}
+
+	if err := state.removeClaimSpec("claim-uid"); err != nil {
+		t.Fatalf("removeClaimSpec: %v", err)
+	}
+	if _, err := os.Stat(specFiles[0]); !os.IsNotExist(err) {
+		t.Errorf("claim spec %v not removed: %v", specFiles[0], err)
+	}
+	if err := state.cdi.Refresh(); err != nil {
+		t.Fatalf("refreshing CDI registry: %v", err)
+	}
+	if state.cdi.DeviceDB().GetDevice(name) != nil {
+		t.Errorf("CDI device %v still in registry", name)
+	}
+}
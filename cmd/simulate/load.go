@@ -0,0 +1,199 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	resourcev1alpha1 "k8s.io/api/resource/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+
+	myscheme "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/clientset/versioned/scheme"
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/conversion"
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+// objects_t holds everything loaded from the input files, in file order
+type objects_t struct {
+	mass            []*v1alpha.MydeviceAllocationState
+	classParameters []*v1alpha.MydeviceClassParameters
+	claimParameters []*v1alpha.MydeviceClaimParameters
+	quotas          []*v1alpha.MydeviceQuota
+	classes         []*resourcev1alpha1.ResourceClass
+	claims          []*resourcev1alpha1.ResourceClaim
+	claimTemplates  []*resourcev1alpha1.ResourceClaimTemplate
+	pods            []*corev1.Pod
+}
+
+var decoder runtime.Decoder
+
+func init() {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(myscheme.AddToScheme(scheme))
+	decoder = serializer.NewCodecFactory(scheme).UniversalDeserializer()
+}
+
+// Load objects from YAML or JSON files, directories are read non-recursively
+func loadObjects(paths []string) (*objects_t, error) {
+	objects := &objects_t{}
+	for _, path := range paths {
+		files, err := expandPath(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			err := objects.loadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %v", file, err)
+			}
+		}
+	}
+	return objects, nil
+}
+
+func expandPath(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	files := []string{}
+	for _, pattern := range []string{"*.yaml", "*.yml", "*.json"} {
+		matches, err := filepath.Glob(filepath.Join(path, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (o *objects_t) loadFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	documents := yaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		var raw runtime.RawExtension
+		err := documents.Decode(&raw)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(raw.Raw) == 0 || string(raw.Raw) == "null" {
+			continue
+		}
+
+		err = o.add(raw.Raw)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Add a JSON encoded object, lists are added item by item. Objects of this
+// driver in other API versions, e.g. snapshots of the storage version, are
+// converted to the version the controller works with.
+func (o *objects_t) add(raw []byte) error {
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return fmt.Errorf("could not decode object type: %v", err)
+	}
+
+	if typeMeta.Kind == "List" {
+		list := &corev1.List{}
+		if err := json.Unmarshal(raw, list); err != nil {
+			return fmt.Errorf("could not decode list: %v", err)
+		}
+		for _, item := range list.Items {
+			if err := o.add(item.Raw); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if mycrd.IsParametersAPIGroup(typeMeta.APIVersion) {
+		converted, err := conversion.Convert(raw, v1alpha.SchemeGroupVersion.String())
+		if err != nil {
+			return err
+		}
+		raw = converted
+	}
+
+	obj, _, err := decoder.Decode(raw, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	// like kubectl, namespaced objects without namespace go into the default one
+	if accessor, ok := obj.(metav1.Object); ok && accessor.GetNamespace() == "" && namespaced(obj) {
+		accessor.SetNamespace(metav1.NamespaceDefault)
+	}
+
+	switch obj := obj.(type) {
+	case *v1alpha.MydeviceAllocationState:
+		o.mass = append(o.mass, obj)
+	case *v1alpha.MydeviceClassParameters:
+		o.classParameters = append(o.classParameters, obj)
+	case *v1alpha.MydeviceClaimParameters:
+		o.claimParameters = append(o.claimParameters, obj)
+	case *v1alpha.MydeviceQuota:
+		o.quotas = append(o.quotas, obj)
+	case *resourcev1alpha1.ResourceClass:
+		o.classes = append(o.classes, obj)
+	case *resourcev1alpha1.ResourceClaim:
+		o.claims = append(o.claims, obj)
+	case *resourcev1alpha1.ResourceClaimTemplate:
+		o.claimTemplates = append(o.claimTemplates, obj)
+	case *corev1.Pod:
+		o.pods = append(o.pods, obj)
+	default:
+		return fmt.Errorf("unsupported object %v %v", typeMeta.APIVersion, typeMeta.Kind)
+	}
+	return nil
+}
+
+func namespaced(obj runtime.Object) bool {
+	switch obj.(type) {
+	case *v1alpha.MydeviceClassParameters, *resourcev1alpha1.ResourceClass:
+		return false
+	}
+	return true
+}
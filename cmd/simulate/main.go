@@ -0,0 +1,116 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/component-base/cli"
+	cliflag "k8s.io/component-base/cli/flag"
+	"k8s.io/component-base/featuregate"
+	"k8s.io/component-base/logs"
+	logsapi "k8s.io/component-base/logs/api/v1"
+	"k8s.io/component-base/term"
+)
+
+type flags_t struct {
+	namespace *string
+}
+
+func main() {
+	command := newCommand()
+	code := cli.Run(command)
+	os.Exit(code)
+}
+
+// NewCommand creates a *cobra.Command object with default parameters.
+func newCommand() *cobra.Command {
+	logsconfig := logsapi.NewLoggingConfiguration()
+	fgate := featuregate.NewFeatureGate()
+	utilruntime.Must(logsapi.AddFeatureGates(fgate))
+
+	cmd := &cobra.Command{
+		Use:   "simulate FILE...",
+		Short: "Example Mydevice resource-driver allocation simulator",
+		Long: `Example Mydevice resource-driver allocation simulator runs the controller allocation logic offline.
+
+It loads MydeviceAllocationState snapshots, ResourceClasses, class and claim
+parameters, MydeviceQuotas, ResourceClaims, ResourceClaimTemplates and pending
+Pods from YAML files, e.g. as written by "kubectl get -o yaml", into fake
+clientsets. Unallocated claims with immediate allocation are allocated first,
+then the pods are scheduled in file order onto the first node that the driver
+finds suitable. Allocations and the reasons nodes were rejected are printed.`,
+		Args: cobra.MinimumNArgs(1),
+	}
+
+	flags := addFlags(cmd, logsconfig, fgate)
+
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// Activate logging as soon as possible, after that
+		// show flags with the final logging configuration.
+		if err := logsapi.ValidateAndApply(logsconfig, fgate); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		objects, err := loadObjects(args)
+		if err != nil {
+			return fmt.Errorf("load objects: %v", err)
+		}
+
+		sim, err := newSimulator(objects, *flags.namespace)
+		if err != nil {
+			return fmt.Errorf("create simulator: %v", err)
+		}
+
+		return sim.run(cmd.Context(), cmd.OutOrStdout())
+	}
+
+	return cmd
+}
+
+func addFlags(cmd *cobra.Command, logsconfig *logsapi.LoggingConfiguration, fgate featuregate.MutableFeatureGate) *flags_t {
+	flags := &flags_t{}
+
+	sharedFlagSets := cliflag.NamedFlagSets{}
+	fs := sharedFlagSets.FlagSet("logging")
+	logsapi.AddFlags(logsconfig, fs)
+	logs.AddFlags(fs, logs.SkipLoggingConfigurationFlags())
+
+	fs = sharedFlagSets.FlagSet("simulation")
+	flags.namespace = fs.String("namespace", "", "Namespace of the MydeviceAllocationState objects, defaults to the namespace of the first one loaded.")
+
+	fs = sharedFlagSets.FlagSet("other")
+	fgate.AddFlag(fs)
+
+	fs = cmd.PersistentFlags()
+	for _, f := range sharedFlagSets.FlagSets {
+		fs.AddFlagSet(f)
+	}
+
+	cols, _, _ := term.TerminalSize(cmd.OutOrStdout())
+	cliflag.SetUsageAndHelpFunc(cmd, sharedFlagSets, cols)
+
+	return flags
+}
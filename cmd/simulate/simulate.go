@@ -0,0 +1,460 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	resourcev1alpha1 "k8s.io/api/resource/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	corefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/dynamic-resource-allocation/controller"
+	"k8s.io/klog/v2"
+
+	mycontroller "github.com/kubernetes-sigs/dra-example-driver/pkg/controller"
+	myfake "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/clientset/versioned/fake"
+	myinformers "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/informers/externalversions"
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+// pending claim requests never expire during a simulation
+const pendingClaimRequestTTL = 24 * time.Hour
+
+type simulator struct {
+	objects       *objects_t
+	namespace     string
+	coreclient    *corefake.Clientset
+	exampleclient *myfake.Clientset
+	driver        *mycontroller.Driver
+	informers     *mycontroller.Informers
+	classes       map[string]*resourcev1alpha1.ResourceClass
+	nodes         []string
+	// last resource version given to an example object
+	resourceVersion int
+}
+
+func newSimulator(objects *objects_t, namespace string) (*simulator, error) {
+	if len(objects.mass) == 0 {
+		return nil, fmt.Errorf("no MydeviceAllocationState snapshots given")
+	}
+	if namespace == "" {
+		namespace = objects.mass[0].Namespace
+	}
+
+	s := &simulator{
+		objects:   objects,
+		namespace: namespace,
+		classes:   make(map[string]*resourcev1alpha1.ResourceClass),
+	}
+
+	coreObjects := []runtime.Object{}
+	for _, class := range objects.classes {
+		s.classes[class.Name] = class
+		coreObjects = append(coreObjects, class)
+	}
+	for _, claim := range s.claims() {
+		coreObjects = append(coreObjects, claim)
+	}
+	for _, pod := range objects.pods {
+		coreObjects = append(coreObjects, pod)
+	}
+
+	s.coreclient = corefake.NewSimpleClientset(coreObjects...)
+	s.exampleclient = myfake.NewSimpleClientset()
+	// the driver prefers its own MAS writes over the informer cache by resource version,
+	// which the fake clientset does not maintain
+	s.exampleclient.PrependReactor("*", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action, ok := action.(interface{ GetObject() runtime.Object }); ok {
+			s.setResourceVersion(action.GetObject())
+		}
+		return false, nil, nil
+	})
+
+	for _, mas := range objects.mass {
+		if mas.Namespace != namespace {
+			klog.Warningf("Ignoring MydeviceAllocationState %v/%v outside of namespace %v", mas.Namespace, mas.Name, namespace)
+			continue
+		}
+		s.nodes = append(s.nodes, mas.Name)
+		if err := s.addExampleObject("mydeviceallocationstates", mas, mas.Namespace); err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(s.nodes)
+	for _, params := range objects.classParameters {
+		if err := s.addExampleObject("mydeviceclassparameters", params, ""); err != nil {
+			return nil, err
+		}
+	}
+	for _, params := range objects.claimParameters {
+		if err := s.addExampleObject("mydeviceclaimparameters", params, params.Namespace); err != nil {
+			return nil, err
+		}
+	}
+	for _, quota := range objects.quotas {
+		if err := s.addExampleObject("mydevicequotas", quota, quota.Namespace); err != nil {
+			return nil, err
+		}
+	}
+
+	s.informers = &mycontroller.Informers{
+		Core:                 informers.NewSharedInformerFactory(s.coreclient, 0 /* resync period */),
+		Example:              myinformers.NewSharedInformerFactoryWithOptions(s.exampleclient, 0 /* resync period */, myinformers.WithNamespace(namespace)),
+		ExampleAllNamespaces: myinformers.NewSharedInformerFactory(s.exampleclient, 0 /* resync period */),
+	}
+
+	config := &mycontroller.Config{
+		Namespace:              namespace,
+		Clientset:              s.exampleclient,
+		CoreClient:             s.coreclient,
+		PendingClaimRequestTTL: pendingClaimRequestTTL,
+	}
+	// events are not of interest, a FakeRecorder without channel drops them
+	driver, err := mycontroller.NewDriver(config, &record.FakeRecorder{}, s.informers)
+	if err != nil {
+		return nil, err
+	}
+	s.driver = driver
+
+	return s, nil
+}
+
+// The fake clientset guesses resources from kinds and gets the plural of the
+// parameters kinds wrong, so objects are added with their resource
+func (s *simulator) addExampleObject(resource string, obj runtime.Object, namespace string) error {
+	s.setResourceVersion(obj)
+	err := s.exampleclient.Tracker().Create(v1alpha.SchemeGroupVersion.WithResource(resource), obj, namespace)
+	if err != nil {
+		return fmt.Errorf("error adding %v: %v", resource, err)
+	}
+	return nil
+}
+
+func (s *simulator) setResourceVersion(obj runtime.Object) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+	s.resourceVersion++
+	accessor.SetResourceVersion(strconv.Itoa(s.resourceVersion))
+}
+
+// All claims, including those the pods get from claim templates. Claims
+// without UID get a predictable one so that runs can be compared.
+func (s *simulator) claims() []*resourcev1alpha1.ResourceClaim {
+	claims := []*resourcev1alpha1.ResourceClaim{}
+	for _, claim := range s.objects.claims {
+		claims = append(claims, claim)
+	}
+
+	templates := make(map[string]*resourcev1alpha1.ResourceClaimTemplate)
+	for _, template := range s.objects.claimTemplates {
+		templates[template.Namespace+"/"+template.Name] = template
+	}
+	for _, pod := range s.objects.pods {
+		for _, podClaim := range pod.Spec.ResourceClaims {
+			if podClaim.Source.ResourceClaimTemplateName == nil {
+				continue
+			}
+			template, exists := templates[pod.Namespace+"/"+*podClaim.Source.ResourceClaimTemplateName]
+			if !exists {
+				klog.Warningf("Pod %v/%v references unknown ResourceClaimTemplate %v", pod.Namespace, pod.Name, *podClaim.Source.ResourceClaimTemplateName)
+				continue
+			}
+			isController := true
+			claims = append(claims, &resourcev1alpha1.ResourceClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      pod.Name + "-" + podClaim.Name,
+					Namespace: pod.Namespace,
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: "v1",
+						Kind:       "Pod",
+						Name:       pod.Name,
+						UID:        pod.UID,
+						Controller: &isController,
+					}},
+				},
+				Spec: template.Spec.Spec,
+			})
+		}
+	}
+
+	for _, claim := range claims {
+		if claim.UID == "" {
+			claim.UID = types.UID("simulated-" + claim.Namespace + "-" + claim.Name)
+		}
+	}
+	return claims
+}
+
+func (s *simulator) run(ctx context.Context, out io.Writer) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s.informers.Start(ctx.Done())
+	err := s.informers.WaitForCacheSync(ctx.Done())
+	if err != nil {
+		return err
+	}
+	// snapshots may hold requests picked by the controller for pending claims
+	err = s.driver.RestorePendingClaimRequests()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Nodes: %v\n", strings.Join(s.nodes, ", "))
+
+	// claims with immediate allocation do not wait for a pod
+	for _, claim := range s.objects.claims {
+		if claim.Spec.AllocationMode != resourcev1alpha1.AllocationModeImmediate || claim.Status.Allocation != nil {
+			continue
+		}
+		ca, err := s.claimAllocation(ctx, claim)
+		if err != nil {
+			fmt.Fprintf(out, "\nClaim %v/%v: %v\n", claim.Namespace, claim.Name, err)
+			continue
+		}
+		if ca == nil {
+			continue
+		}
+
+		fmt.Fprintf(out, "\nClaim %v/%v (immediate):\n", claim.Namespace, claim.Name)
+		s.allocate(ctx, out, nil, ca, "")
+	}
+
+	for _, pod := range s.objects.pods {
+		s.schedulePod(ctx, out, pod)
+	}
+
+	return s.printAllocations(ctx, out)
+}
+
+// Build the claim allocation the DRA controller would pass to the driver,
+// nil if the claim is not handled by this driver
+func (s *simulator) claimAllocation(ctx context.Context, claim *resourcev1alpha1.ResourceClaim) (*controller.ClaimAllocation, error) {
+	class, exists := s.classes[claim.Spec.ResourceClassName]
+	if !exists {
+		return nil, fmt.Errorf("unknown ResourceClass %v", claim.Spec.ResourceClassName)
+	}
+	if class.DriverName != mycrd.ApiGroupName {
+		return nil, nil
+	}
+
+	classParameters, err := s.driver.GetClassParameters(ctx, class)
+	if err != nil {
+		return nil, err
+	}
+	claimParameters, err := s.driver.GetClaimParameters(ctx, claim, class, classParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &controller.ClaimAllocation{
+		Claim:           claim,
+		Class:           class,
+		ClassParameters: classParameters,
+		ClaimParameters: claimParameters,
+	}, nil
+}
+
+// Ask the driver which nodes suit the unallocated claims of the pod with
+// delayed allocation and allocate them on the first suitable node
+func (s *simulator) schedulePod(ctx context.Context, out io.Writer, pod *corev1.Pod) {
+	fmt.Fprintf(out, "\nPod %v/%v:\n", pod.Namespace, pod.Name)
+
+	cas := []*controller.ClaimAllocation{}
+	for _, podClaim := range pod.Spec.ResourceClaims {
+		claim, err := s.podClaim(ctx, pod, podClaim)
+		if err != nil {
+			fmt.Fprintf(out, "  claim %v: %v\n", podClaim.Name, err)
+			return
+		}
+		if claim.Status.Allocation != nil {
+			fmt.Fprintf(out, "  claim %v/%v: already allocated\n", claim.Namespace, claim.Name)
+			continue
+		}
+		if claim.Spec.AllocationMode == resourcev1alpha1.AllocationModeImmediate {
+			fmt.Fprintf(out, "  claim %v/%v: immediate allocation failed, pod cannot run\n", claim.Namespace, claim.Name)
+			return
+		}
+
+		ca, err := s.claimAllocation(ctx, claim)
+		if err != nil {
+			fmt.Fprintf(out, "  claim %v/%v: %v\n", claim.Namespace, claim.Name, err)
+			return
+		}
+		if ca != nil {
+			cas = append(cas, ca)
+		}
+	}
+	if len(cas) == 0 {
+		fmt.Fprintf(out, "  no claims to allocate\n")
+		return
+	}
+
+	potentialNodes := s.nodes
+	if pod.Spec.NodeName != "" {
+		potentialNodes = []string{pod.Spec.NodeName}
+	}
+
+	reasons, err := s.driver.UnsuitableNodeReasons(ctx, pod, cas, potentialNodes)
+	if err != nil {
+		fmt.Fprintf(out, "  error checking nodes: %v\n", err)
+		return
+	}
+
+	unsuitable := make(map[string]bool)
+	for _, ca := range cas {
+		for _, node := range ca.UnsuitableNodes {
+			unsuitable[node] = true
+		}
+	}
+	for _, node := range potentialNodes {
+		if reason, exists := reasons[node]; exists {
+			fmt.Fprintf(out, "  node %v rejected: %v\n", node, reason)
+		}
+	}
+
+	selectedNode := ""
+	for _, node := range potentialNodes {
+		if !unsuitable[node] {
+			selectedNode = node
+			break
+		}
+	}
+	if selectedNode == "" {
+		fmt.Fprintf(out, "  no suitable node, pod stays pending\n")
+		return
+	}
+
+	fmt.Fprintf(out, "  scheduled on node %v\n", selectedNode)
+	for _, ca := range cas {
+		s.allocate(ctx, out, pod, ca, selectedNode)
+	}
+}
+
+// Claim used by the pod, generated from a template or referenced by name
+func (s *simulator) podClaim(ctx context.Context, pod *corev1.Pod, podClaim corev1.PodResourceClaim) (*resourcev1alpha1.ResourceClaim, error) {
+	name := pod.Name + "-" + podClaim.Name
+	if podClaim.Source.ResourceClaimName != nil {
+		name = *podClaim.Source.ResourceClaimName
+	}
+	return s.coreclient.ResourceV1alpha1().ResourceClaims(pod.Namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// Allocate the claim and write the result into the claim status, like the DRA controller does
+func (s *simulator) allocate(ctx context.Context, out io.Writer, pod *corev1.Pod, ca *controller.ClaimAllocation, selectedNode string) {
+	claim := ca.Claim
+	result, err := s.driver.Allocate(ctx, claim, ca.ClaimParameters, ca.Class, ca.ClassParameters, selectedNode)
+	if err != nil {
+		fmt.Fprintf(out, "  claim %v/%v not allocated: %v\n", claim.Namespace, claim.Name, err)
+		return
+	}
+
+	handle, err := mycrd.DecodeResourceHandle(result.ResourceHandle)
+	if err != nil {
+		fmt.Fprintf(out, "  claim %v/%v: %v\n", claim.Namespace, claim.Name, err)
+		return
+	}
+	uids := []string{}
+	for _, device := range handle.Mydevices {
+		uids = append(uids, device.UID)
+	}
+	fmt.Fprintf(out, "  claim %v/%v allocated on node %v: %v\n", claim.Namespace, claim.Name, nodeOf(result), strings.Join(uids, ", "))
+
+	claim = claim.DeepCopy()
+	claim.Status.DriverName = ca.Class.DriverName
+	claim.Status.Allocation = result
+	if pod != nil {
+		claim.Status.ReservedFor = append(claim.Status.ReservedFor, resourcev1alpha1.ResourceClaimConsumerReference{
+			Resource: "pods",
+			Name:     pod.Name,
+			UID:      pod.UID,
+		})
+	}
+	_, err = s.coreclient.ResourceV1alpha1().ResourceClaims(claim.Namespace).UpdateStatus(ctx, claim, metav1.UpdateOptions{})
+	if err != nil {
+		klog.Errorf("Error updating status of claim %v/%v: %v", claim.Namespace, claim.Name, err)
+	}
+}
+
+func nodeOf(result *resourcev1alpha1.AllocationResult) string {
+	if result.AvailableOnNodes == nil || len(result.AvailableOnNodes.NodeSelectorTerms) == 0 {
+		return ""
+	}
+	term := result.AvailableOnNodes.NodeSelectorTerms[0]
+	if len(term.MatchFields) == 0 || len(term.MatchFields[0].Values) == 0 {
+		return ""
+	}
+	return term.MatchFields[0].Values[0]
+}
+
+// Print devices of each node with the claims holding them after the simulation
+func (s *simulator) printAllocations(ctx context.Context, out io.Writer) error {
+	claimNames := make(map[string]string)
+	claims, err := s.coreclient.ResourceV1alpha1().ResourceClaims("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing claims: %v", err)
+	}
+	for _, claim := range claims.Items {
+		claimNames[string(claim.UID)] = claim.Namespace + "/" + claim.Name
+	}
+
+	fmt.Fprintf(out, "\nAllocations:\n")
+	for _, node := range s.nodes {
+		mas := mycrd.NewMydeviceAllocationState(&mycrd.MydeviceAllocationStateConfig{Name: node, Namespace: s.namespace}, s.exampleclient)
+		if err := mas.Get(); err != nil {
+			return fmt.Errorf("error getting MydeviceAllocationState %v: %v", node, err)
+		}
+
+		fmt.Fprintf(out, "  node %v: %d of %d devices free\n", node, len(mas.Available()), len(mas.Spec.AllocatableMydevices))
+		claimUIDs := []string{}
+		for claimUID := range mas.Spec.ResourceClaimAllocations {
+			claimUIDs = append(claimUIDs, claimUID)
+		}
+		sort.Strings(claimUIDs)
+		for _, claimUID := range claimUIDs {
+			name, exists := claimNames[claimUID]
+			if !exists {
+				name = claimUID
+			}
+			uids := []string{}
+			for _, device := range mas.Spec.ResourceClaimAllocations[claimUID] {
+				uids = append(uids, device.UID)
+			}
+			fmt.Fprintf(out, "    %v: %v\n", name, strings.Join(uids, ", "))
+		}
+	}
+	return nil
+}
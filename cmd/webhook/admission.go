@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// admitFunc validates a single admission request. Returned error denies the
+// request and is shown to the user, warnings are shown either way.
+type admitFunc func(ctx context.Context, req *admissionv1.AdmissionRequest) ([]string, error)
+
+// Decode the AdmissionReview, validate the request and write the review response
+func serveAdmission(admit admitFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("error reading request body: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		review := &admissionv1.AdmissionReview{}
+		err = json.Unmarshal(body, review)
+		if err != nil || review.Request == nil {
+			http.Error(w, fmt.Sprintf("error decoding AdmissionReview: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		req := review.Request
+		klog.V(5).InfoS("Admission request", "kind", req.Kind.Kind, "namespace", req.Namespace, "name", req.Name, "operation", req.Operation)
+
+		response := &admissionv1.AdmissionResponse{
+			UID:     req.UID,
+			Allowed: true,
+		}
+		warnings, err := admit(r.Context(), req)
+		if err != nil {
+			klog.V(3).InfoS("Admission request denied", "kind", req.Kind.Kind, "namespace", req.Namespace, "name", req.Name, "reason", err)
+			response.Allowed = false
+			response.Result = &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: err.Error(),
+				Reason:  metav1.StatusReasonInvalid,
+				Code:    http.StatusUnprocessableEntity,
+			}
+		}
+		response.Warnings = warnings
+
+		out, err := json.Marshal(&admissionv1.AdmissionReview{
+			TypeMeta: review.TypeMeta,
+			Response: response,
+		})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("error encoding AdmissionReview: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, err = w.Write(out)
+		if err != nil {
+			klog.Errorf("Error writing admission response: %v", err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/conversion"
+)
+
+// conversionReview mirrors apiextensions.k8s.io/v1 ConversionReview,
+// which is not worth pulling in apiextensions-apiserver for
+type conversionReview struct {
+	metav1.TypeMeta `json:",inline"`
+	Request         *conversionRequest  `json:"request,omitempty"`
+	Response        *conversionResponse `json:"response,omitempty"`
+}
+
+type conversionRequest struct {
+	UID               types.UID              `json:"uid"`
+	DesiredAPIVersion string                 `json:"desiredAPIVersion"`
+	Objects           []runtime.RawExtension `json:"objects"`
+}
+
+type conversionResponse struct {
+	UID              types.UID              `json:"uid"`
+	ConvertedObjects []runtime.RawExtension `json:"convertedObjects"`
+	Result           metav1.Status          `json:"result"`
+}
+
+// Convert all objects of the ConversionReview, a single failure fails the whole review
+func serveConversion(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error reading request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	review := &conversionReview{}
+	err = json.Unmarshal(body, review)
+	if err != nil || review.Request == nil {
+		http.Error(w, fmt.Sprintf("error decoding ConversionReview: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	req := review.Request
+	klog.V(5).InfoS("Conversion request", "objects", len(req.Objects), "desiredAPIVersion", req.DesiredAPIVersion)
+
+	response := &conversionResponse{
+		UID:    req.UID,
+		Result: metav1.Status{Status: metav1.StatusSuccess},
+	}
+	for _, object := range req.Objects {
+		converted, err := conversion.Convert(object.Raw, req.DesiredAPIVersion)
+		if err != nil {
+			klog.Errorf("Error converting object to %v: %v", req.DesiredAPIVersion, err)
+			response.ConvertedObjects = nil
+			response.Result = metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: err.Error(),
+			}
+			break
+		}
+		response.ConvertedObjects = append(response.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+
+	out, err := json.Marshal(&conversionReview{
+		TypeMeta: review.TypeMeta,
+		Response: response,
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error encoding ConversionReview: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(out)
+	if err != nil {
+		klog.Errorf("Error writing conversion response: %v", err)
+	}
+}
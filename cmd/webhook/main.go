@@ -0,0 +1,221 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	coreclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/component-base/cli"
+	cliflag "k8s.io/component-base/cli/flag"
+	"k8s.io/component-base/featuregate"
+	"k8s.io/component-base/logs"
+	logsapi "k8s.io/component-base/logs/api/v1"
+	_ "k8s.io/component-base/logs/json/register" // for JSON log output support
+	"k8s.io/component-base/term"
+	"k8s.io/klog/v2"
+
+	myclientset "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/clientset/versioned"
+)
+
+type flags_t struct {
+	kubeconfig   *string
+	kubeAPIQPS   *float32
+	kubeAPIBurst *int
+
+	port        *int
+	tlsCertFile *string
+	tlsKeyFile  *string
+}
+
+type clientset_t struct {
+	core    coreclientset.Interface
+	example myclientset.Interface
+}
+
+type config_t struct {
+	namespace string
+	flags     *flags_t
+	clientset *clientset_t
+}
+
+func main() {
+	command := newCommand()
+	code := cli.Run(command)
+	os.Exit(code)
+}
+
+// NewCommand creates a *cobra.Command object with default parameters.
+func newCommand() *cobra.Command {
+	logsconfig := logsapi.NewLoggingConfiguration()
+	fgate := featuregate.NewFeatureGate()
+	utilruntime.Must(logsapi.AddFeatureGates(fgate))
+
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "Example Mydevice resource-driver admission webhook",
+		Long:  "Example Mydevice resource-driver admission webhook validates driver parameters and resource-claims of driver resource-classes, and converts driver objects between API versions",
+	}
+
+	flags := addFlags(cmd, logsconfig, fgate)
+
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// Activate logging as soon as possible, after that
+		// show flags with the final logging configuration.
+		if err := logsapi.ValidateAndApply(logsconfig, fgate); err != nil {
+			return err
+		}
+
+		if *flags.tlsCertFile == "" || *flags.tlsKeyFile == "" {
+			return fmt.Errorf("--tls-cert-file and --tls-private-key-file are required")
+		}
+
+		return nil
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		csconfig, err := getClientsetConfig(flags)
+		if err != nil {
+			return fmt.Errorf("create client configuration: %v", err)
+		}
+
+		coreclient, err := coreclientset.NewForConfig(csconfig)
+		if err != nil {
+			return fmt.Errorf("create core client: %v", err)
+		}
+
+		myclient, err := myclientset.NewForConfig(csconfig)
+		if err != nil {
+			return fmt.Errorf("create Example client: %v", err)
+		}
+
+		nsname, nsnamefound := os.LookupEnv("POD_NAMESPACE")
+		if !nsnamefound {
+			nsname = "default"
+		}
+
+		config := &config_t{
+			flags:     flags,
+			namespace: nsname,
+			clientset: &clientset_t{
+				coreclient,
+				myclient,
+			},
+		}
+
+		return runServer(config)
+	}
+
+	return cmd
+}
+
+func addFlags(cmd *cobra.Command, logsconfig *logsapi.LoggingConfiguration, fgate featuregate.MutableFeatureGate) *flags_t {
+	flags := &flags_t{}
+
+	sharedFlagSets := cliflag.NamedFlagSets{}
+	fs := sharedFlagSets.FlagSet("logging")
+	logsapi.AddFlags(logsconfig, fs)
+	logs.AddFlags(fs, logs.SkipLoggingConfigurationFlags())
+
+	fs = sharedFlagSets.FlagSet("Kubernetes client")
+	flags.kubeconfig = fs.String("kubeconfig", "", "Absolute path to the kube.config file. Either this or KUBECONFIG need to be set if the webhook is being run out of cluster.")
+	flags.kubeAPIQPS = fs.Float32("kube-api-qps", 5, "QPS to use while communicating with the kubernetes apiserver.")
+	flags.kubeAPIBurst = fs.Int("kube-api-burst", 10, "Burst to use while communicating with the kubernetes apiserver.")
+
+	fs = sharedFlagSets.FlagSet("webhook server")
+	flags.port = fs.Int("port", 8443, "The port the HTTPS server for admission requests listens on.")
+	flags.tlsCertFile = fs.String("tls-cert-file", "", "File containing the x509 certificate for HTTPS.")
+	flags.tlsKeyFile = fs.String("tls-private-key-file", "", "File containing the x509 private key matching --tls-cert-file.")
+
+	fs = sharedFlagSets.FlagSet("other")
+	fgate.AddFlag(fs)
+
+	fs = cmd.PersistentFlags()
+	for _, f := range sharedFlagSets.FlagSets {
+		fs.AddFlagSet(f)
+	}
+
+	// SetUsageAndHelpFunc takes care of flag grouping. However,
+	// it doesn't support listing child commands. We add those
+	// to cmd.Use.
+	cols, _, _ := term.TerminalSize(cmd.OutOrStdout())
+	cliflag.SetUsageAndHelpFunc(cmd, sharedFlagSets, cols)
+
+	return flags
+}
+
+func getClientsetConfig(f *flags_t) (*rest.Config, error) {
+	var csconfig *rest.Config
+
+	klog.V(5).Infof("Getting client config")
+
+	kubeconfigEnv := os.Getenv("KUBECONFIG")
+	if kubeconfigEnv != "" {
+		klog.V(5).Infof("Found KUBECONFIG environment variable set, using that..")
+		*f.kubeconfig = kubeconfigEnv
+	}
+
+	var err error
+	if *f.kubeconfig == "" {
+		csconfig, err = rest.InClusterConfig()
+		if err != nil {
+			return nil, fmt.Errorf("create in-cluster client configuration: %v", err)
+		}
+	} else {
+		csconfig, err = clientcmd.BuildConfigFromFlags("", *f.kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("create out-of-cluster client configuration: %v", err)
+		}
+	}
+
+	csconfig.QPS = *f.kubeAPIQPS
+	csconfig.Burst = *f.kubeAPIBurst
+
+	return csconfig, nil
+}
+
+func runServer(config *config_t) error {
+	v := newValidator(config)
+
+	mux := http.NewServeMux()
+	mux.Handle("/validate-mydeviceclaimparameters", serveAdmission(v.validateMydeviceClaimParameters))
+	mux.Handle("/validate-mydeviceclassparameters", serveAdmission(v.validateMydeviceClassParameters))
+	mux.Handle("/validate-resourceclaim", serveAdmission(v.validateResourceClaim))
+	mux.HandleFunc("/convert", serveConversion)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", *config.flags.port),
+		Handler: mux,
+	}
+
+	klog.V(3).InfoS("Starting webhook server", "port", *config.flags.port)
+	err := server.ListenAndServeTLS(*config.flags.tlsCertFile, *config.flags.tlsKeyFile)
+	if err != nil {
+		return fmt.Errorf("webhook server failed: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	resourcev1alpha1 "k8s.io/api/resource/v1alpha1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+type validator struct {
+	namespace string
+	clientset *clientset_t
+}
+
+func newValidator(config *config_t) *validator {
+	return &validator{
+		namespace: config.namespace,
+		clientset: config.clientset,
+	}
+}
+
+func (v *validator) validateMydeviceClaimParameters(ctx context.Context, req *admissionv1.AdmissionRequest) ([]string, error) {
+	params := &v1alpha.MydeviceClaimParameters{}
+	if err := json.Unmarshal(req.Object.Raw, params); err != nil {
+		return nil, fmt.Errorf("could not decode MydeviceClaimParameters: %v", err)
+	}
+
+	if err := mycrd.ValidateMydeviceClaimParametersSpec(&params.Spec); err != nil {
+		return nil, fmt.Errorf("invalid MydeviceClaimParameters spec: %v", err)
+	}
+
+	if req.Operation == admissionv1.Update {
+		old := &v1alpha.MydeviceClaimParameters{}
+		if err := json.Unmarshal(req.OldObject.Raw, old); err != nil {
+			return nil, fmt.Errorf("could not decode old MydeviceClaimParameters: %v", err)
+		}
+		if !apiequality.Semantic.DeepEqual(old.Spec, params.Spec) {
+			if err := v.checkNotReferencedByAllocatedClaim(ctx, req.Namespace, req.Name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return v.checkCapacityInAnyClass(ctx, &params.Spec)
+}
+
+func (v *validator) validateMydeviceClassParameters(ctx context.Context, req *admissionv1.AdmissionRequest) ([]string, error) {
+	params := &v1alpha.MydeviceClassParameters{}
+	if err := json.Unmarshal(req.Object.Raw, params); err != nil {
+		return nil, fmt.Errorf("could not decode MydeviceClassParameters: %v", err)
+	}
+
+	if err := mycrd.ValidateMydeviceClassParametersSpec(&params.Spec); err != nil {
+		return nil, fmt.Errorf("invalid MydeviceClassParameters spec: %v", err)
+	}
+
+	// the smallest possible claim tells whether the selectors match any device at all
+	return v.checkCapacity(ctx, mycrd.DefaultMydeviceClaimParametersSpec(), &params.Spec)
+}
+
+func (v *validator) validateResourceClaim(ctx context.Context, req *admissionv1.AdmissionRequest) ([]string, error) {
+	claim := &resourcev1alpha1.ResourceClaim{}
+	if err := json.Unmarshal(req.Object.Raw, claim); err != nil {
+		return nil, fmt.Errorf("could not decode ResourceClaim: %v", err)
+	}
+
+	class, err := v.clientset.core.ResourceV1alpha1().ResourceClasses().Get(ctx, claim.Spec.ResourceClassName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		// not ours to judge, the class may be created later
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get ResourceClass '%v': %v", claim.Spec.ResourceClassName, err)
+	}
+	if class.DriverName != mycrd.ApiGroupName {
+		return nil, nil
+	}
+
+	classSpec, err := v.getClassParametersSpec(ctx, class)
+	if err != nil {
+		return nil, err
+	}
+	if classSpec == nil {
+		return []string{fmt.Sprintf("MydeviceClassParameters '%v' of ResourceClass '%v' not found, claim was not validated", class.ParametersRef.Name, class.Name)}, nil
+	}
+
+	claimSpec := mycrd.DefaultMydeviceClaimParametersSpec()
+	if ref := claim.Spec.ParametersRef; ref != nil {
+		if !mycrd.IsParametersAPIGroup(ref.APIGroup) {
+			return nil, fmt.Errorf("incorrect claim spec parameter API group and version: %v, expected one of: %v", ref.APIGroup, mycrd.ParametersAPIGroups)
+		}
+		if ref.Kind != mycrd.MydeviceClaimParametersKind {
+			return nil, fmt.Errorf("unsupported ResourceClaim.ParametersRef Kind: %v, expected: %v", ref.Kind, mycrd.MydeviceClaimParametersKind)
+		}
+		gcp, err := v.clientset.example.DraV1alpha().MydeviceClaimParameters(req.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return []string{fmt.Sprintf("MydeviceClaimParameters '%v' not found, claim will stay pending until it is created", ref.Name)}, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not get MydeviceClaimParameters '%v' in namespace '%v': %v", ref.Name, req.Namespace, err)
+		}
+		if err := mycrd.ValidateMydeviceClaimParametersSpec(&gcp.Spec); err != nil {
+			return nil, fmt.Errorf("invalid MydeviceClaimParameters '%v': %v", ref.Name, err)
+		}
+		claimSpec = &gcp.Spec
+	}
+
+	return v.checkCapacity(ctx, claimSpec, classSpec)
+}
+
+// getClassParametersSpec returns the class parameters the driver allocates claims
+// of the class with, nil if the parameters object does not exist (yet)
+func (v *validator) getClassParametersSpec(ctx context.Context, class *resourcev1alpha1.ResourceClass) (*mycrd.MydeviceClassParametersSpec, error) {
+	if class.ParametersRef == nil {
+		return mycrd.DefaultDeviceClassParametersSpec(), nil
+	}
+	if !mycrd.IsParametersAPIGroup(class.ParametersRef.APIGroup) {
+		return nil, fmt.Errorf("ResourceClass '%v' has incorrect parameters API group and version: %v, expected one of: %v", class.Name, class.ParametersRef.APIGroup, mycrd.ParametersAPIGroups)
+	}
+	dc, err := v.clientset.example.DraV1alpha().MydeviceClassParameters().Get(ctx, class.ParametersRef.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get MydeviceClassParameters '%v': %v", class.ParametersRef.Name, err)
+	}
+	return &dc.Spec, nil
+}
+
+// checkCapacityInAnyClass rejects claim parameters that no resource class of the
+// driver could satisfy. Claim parameters are not bound to a class, the claims
+// referencing them pick it, so fitting one of the classes is enough.
+func (v *validator) checkCapacityInAnyClass(ctx context.Context, claimSpec *mycrd.MydeviceClaimParametersSpec) ([]string, error) {
+	classes, err := v.clientset.core.ResourceV1alpha1().ResourceClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not list ResourceClasses: %v", err)
+	}
+
+	var warnings, reasons []string
+	checked := 0
+	for i := range classes.Items {
+		class := &classes.Items[i]
+		if class.DriverName != mycrd.ApiGroupName {
+			continue
+		}
+		classSpec, err := v.getClassParametersSpec(ctx, class)
+		if err != nil {
+			reasons = append(reasons, err.Error())
+			continue
+		}
+		if classSpec == nil {
+			warnings = append(warnings, fmt.Sprintf("MydeviceClassParameters '%v' of ResourceClass '%v' not found, class was not checked", class.ParametersRef.Name, class.Name))
+			continue
+		}
+		checked++
+		classWarnings, err := v.checkCapacity(ctx, claimSpec, classSpec)
+		if err != nil {
+			reasons = append(reasons, fmt.Sprintf("ResourceClass '%v': %v", class.Name, err))
+			continue
+		}
+		return append(warnings, classWarnings...), nil
+	}
+
+	if len(reasons) == 0 {
+		if checked == 0 {
+			warnings = append(warnings, "no ResourceClass of the driver found, device capacity was not checked")
+		}
+		return warnings, nil
+	}
+	return warnings, fmt.Errorf("no ResourceClass of the driver can satisfy the claim: %v", strings.Join(reasons, "; "))
+}
+
+// checkCapacity rejects requests no node could ever satisfy, even with all of its devices free.
+// Nothing is rejected while no node has published its devices yet.
+func (v *validator) checkCapacity(ctx context.Context, claimSpec *mycrd.MydeviceClaimParametersSpec, classSpec *mycrd.MydeviceClassParametersSpec) ([]string, error) {
+	mass, err := v.clientset.example.DraV1alpha().MydeviceAllocationStates(v.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not list MydeviceAllocationStates: %v", err)
+	}
+
+	maxMatching, nodes := mycrd.MaxMatchingDevices(mass.Items, claimSpec, classSpec)
+	minCount, _ := mycrd.CountRange(claimSpec)
+	klog.V(5).InfoS("Capacity check", "matching", maxMatching, "nodes", nodes, "minCount", minCount)
+	if nodes == 0 {
+		return []string{"no node has published its devices yet, device capacity was not checked"}, nil
+	}
+	if maxMatching == 0 {
+		return nil, fmt.Errorf("no device on any of %d nodes matches the requested type and selectors", nodes)
+	}
+	if minCount > maxMatching {
+		return nil, fmt.Errorf("requested count %d exceeds %d matching devices of the largest node", minCount, maxMatching)
+	}
+	return nil, nil
+}
+
+// checkNotReferencedByAllocatedClaim rejects spec changes of parameters that
+// allocated claims were allocated with, the allocation would no longer match them
+func (v *validator) checkNotReferencedByAllocatedClaim(ctx context.Context, namespace, name string) error {
+	claims, err := v.clientset.core.ResourceV1alpha1().ResourceClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("could not list ResourceClaims in namespace '%v': %v", namespace, err)
+	}
+
+	for _, claim := range claims.Items {
+		ref := claim.Spec.ParametersRef
+		if ref == nil || !mycrd.IsParametersAPIGroup(ref.APIGroup) || ref.Kind != mycrd.MydeviceClaimParametersKind || ref.Name != name {
+			continue
+		}
+		if claim.Status.Allocation != nil {
+			return fmt.Errorf("spec cannot be changed while allocated ResourceClaim '%v' references it", claim.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	resourcev1alpha1 "k8s.io/api/resource/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corefake "k8s.io/client-go/kubernetes/fake"
+
+	myfake "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/clientset/versioned/fake"
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+const testNamespace = "dra-example-driver"
+
+func newTestValidator(t *testing.T, classes []*resourcev1alpha1.ResourceClass, classParams []*v1alpha.MydeviceClassParameters, deviceUIDs ...string) *validator {
+	ctx := context.TODO()
+	coreclient := corefake.NewSimpleClientset()
+	exampleclient := myfake.NewSimpleClientset()
+
+	for _, class := range classes {
+		if _, err := coreclient.ResourceV1alpha1().ResourceClasses().Create(ctx, class, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, params := range classParams {
+		if _, err := exampleclient.DraV1alpha().MydeviceClassParameters().Create(ctx, params, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mas := &v1alpha.MydeviceAllocationState{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1", Namespace: testNamespace},
+		Spec:       v1alpha.MydeviceAllocationStateSpec{AllocatableMydevices: map[string]v1alpha.AllocatableMydevice{}},
+	}
+	for _, uid := range deviceUIDs {
+		mas.Spec.AllocatableMydevices[uid] = v1alpha.AllocatableMydevice{UID: uid, Type: v1alpha.MydeviceType0}
+	}
+	if _, err := exampleclient.DraV1alpha().MydeviceAllocationStates(testNamespace).Create(ctx, mas, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	return &validator{
+		namespace: testNamespace,
+		clientset: &clientset_t{coreclient, exampleclient},
+	}
+}
+
+func testResourceClass(name, driverName, paramsName string) *resourcev1alpha1.ResourceClass {
+	class := &resourcev1alpha1.ResourceClass{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		DriverName: driverName,
+	}
+	if paramsName != "" {
+		class.ParametersRef = &resourcev1alpha1.ResourceClassParametersReference{
+			APIGroup: mycrd.ApiGroupName + "/" + mycrd.ApiVersion,
+			Kind:     "MydeviceClassParameters",
+			Name:     paramsName,
+		}
+	}
+	return class
+}
+
+func testClassParameters(name, namePattern string) *v1alpha.MydeviceClassParameters {
+	return &v1alpha.MydeviceClassParameters{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1alpha.MydeviceClassParametersSpec{
+			MydeviceSelector: []v1alpha.MydeviceSelector{{Type: v1alpha.MydeviceType0, Name: namePattern}},
+		},
+	}
+}
+
+func claimParametersRequest(t *testing.T, spec v1alpha.MydeviceClaimParametersSpec) *admissionv1.AdmissionRequest {
+	raw, err := json.Marshal(&v1alpha.MydeviceClaimParameters{
+		ObjectMeta: metav1.ObjectMeta{Name: "params", Namespace: "default"},
+		Spec:       spec,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Namespace: "default",
+		Name:      "params",
+		Object:    runtime.RawExtension{Raw: raw},
+	}
+}
+
+// Claim parameters are checked against the classes of the driver, not against
+// a class that allows every device
+func TestValidateMydeviceClaimParametersCapacity(t *testing.T) {
+	narrowClass := testResourceClass("narrow", mycrd.ApiGroupName, "dev0-only")
+	narrowParams := testClassParameters("dev0-only", "dev0")
+	wideClass := testResourceClass("wide", mycrd.ApiGroupName, "")
+	otherClass := testResourceClass("other", "other.example.com", "")
+	missingParamsClass := testResourceClass("missing", mycrd.ApiGroupName, "missing")
+
+	testCases := []struct {
+		name        string
+		classes     []*resourcev1alpha1.ResourceClass
+		count       int
+		allowed     bool
+		warningLike string
+	}{
+		{"fits narrow class", []*resourcev1alpha1.ResourceClass{narrowClass}, 1, true, ""},
+		{"exceeds narrow class", []*resourcev1alpha1.ResourceClass{narrowClass}, 2, false, ""},
+		{"fits one of the classes", []*resourcev1alpha1.ResourceClass{narrowClass, wideClass}, 2, true, ""},
+		{"exceeds all classes", []*resourcev1alpha1.ResourceClass{narrowClass, wideClass}, 3, false, ""},
+		{"classes of other drivers ignored", []*resourcev1alpha1.ResourceClass{narrowClass, otherClass}, 2, false, ""},
+		{"no class of the driver", []*resourcev1alpha1.ResourceClass{otherClass}, 3, true, "no ResourceClass of the driver"},
+		{"class parameters missing", []*resourcev1alpha1.ResourceClass{missingParamsClass}, 3, true, "not found"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := newTestValidator(t, tc.classes, []*v1alpha.MydeviceClassParameters{narrowParams}, "dev0", "dev1")
+
+			warnings, err := v.validateMydeviceClaimParameters(context.TODO(), claimParametersRequest(t, v1alpha.MydeviceClaimParametersSpec{Count: tc.count}))
+			if tc.allowed && err != nil {
+				t.Fatalf("expected claim parameters to be allowed, got %v", err)
+			}
+			if !tc.allowed && err == nil {
+				t.Fatalf("expected claim parameters to be denied")
+			}
+			if tc.warningLike != "" && !strings.Contains(strings.Join(warnings, "\n"), tc.warningLike) {
+				t.Errorf("expected warning like '%v', got %v", tc.warningLike, warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+#!/usr/bin/env bash
+
+# Copyright 2023 The Kubernetes Authors.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+# Create a fake sysfs and dev tree with DRM devices, for running device discovery,
+# health checks and hotplug detection of the kubelet plugin without hardware:
+#
+#   hack/create_fake_sysfs.sh /tmp/fake 4
+#   kubelet-plugin --sysfs-root=/tmp/fake/sys --dev-root=/tmp/fake/dev
+#
+# The first half of the devices is attached to PCI root complex pci0000:00 and NUMA
+# node 0, the second half to pci0000:80 and NUMA node 1, devices are paired behind
+# bridges. The last device has no renderD node and card0 has a connector, which
+# discovery must skip. Device nodes are plain files, so mknod and root are not needed.
+#
+# Hotplug can be simulated by removing or re-adding the class/drm/cardN symlinks,
+# health failures by writing 0 to the enable file of a device or removing its driver.
+
+if [ $# -lt 1 ] || [ $# -gt 2 ]; then
+    echo "Usage: $0 ROOT [DEVICES]" >&2
+    exit 1
+fi
+
+ROOT=$1
+DEVICES=${2:-4}
+SYSFS="$ROOT/sys"
+DEV="$ROOT/dev"
+
+VENDOR_ID="0x8086"
+DEVICE_ID="0x56a0"
+
+mkdir -p "$SYSFS/class/drm" "$SYSFS/bus/pci/devices" "$SYSFS/bus/pci/drivers/i915" "$DEV/dri"
+echo "drm 1.1.0 20060810" > "$SYSFS/class/drm/version"
+
+for ((idx = 0; idx < DEVICES; idx++)); do
+    if ((idx < (DEVICES + 1) / 2)); then
+        domain="0000:00"
+        numa_node=0
+    else
+        domain="0000:80"
+        numa_node=1
+    fi
+    root_complex="pci$domain"
+    bridge="$domain:$(printf '%02x' $((idx / 2 + 1))).0"
+    address="0000:$(printf '%02x' $((idx + 1))):00.0"
+    device_path="devices/$root_complex/$bridge/$address"
+    device_dir="$SYSFS/$device_path"
+
+    mkdir -p "$device_dir/drm/card$idx"
+    echo "$VENDOR_ID" > "$device_dir/vendor"
+    echo "$DEVICE_ID" > "$device_dir/device"
+    echo "$numa_node" > "$device_dir/numa_node"
+    echo 1 > "$device_dir/enable"
+    ln -sfn "../../../../bus/pci/drivers/i915" "$device_dir/driver"
+    ln -sfn "../../../$device_path" "$SYSFS/bus/pci/devices/$address"
+
+    ln -sfn "../../$device_path/drm/card$idx" "$SYSFS/class/drm/card$idx"
+    touch "$DEV/dri/card$idx"
+
+    if ((idx < DEVICES - 1)); then
+        renderd="renderD$((128 + idx))"
+        mkdir -p "$device_dir/drm/$renderd"
+        ln -sfn "../../$device_path/drm/$renderd" "$SYSFS/class/drm/$renderd"
+        touch "$DEV/dri/$renderd"
+    fi
+
+    if ((idx == 0)); then
+        mkdir -p "$device_dir/drm/card0/card0-DP-1"
+        ln -sfn "../../$device_path/drm/card0/card0-DP-1" "$SYSFS/class/drm/card0-DP-1"
+    fi
+done
+
+echo "Created $DEVICES fake DRM devices in $SYSFS and $DEV"
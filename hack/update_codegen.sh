@@ -28,12 +28,12 @@ cd "$EXAMPLE_DRIVER"
 # If this fails like "Failed making a parser: unable to add directory", "No files for pkg", then
 # remove previously installed dra-example-driver from $GOPATH/src/
 
-API_VERSION=v1alpha
+API_VERSIONS="v1alpha v1beta1"
 bash vendor/k8s.io/code-generator/generate-groups.sh \
   "all" \
   github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example \
   github.com/kubernetes-sigs/dra-example-driver/pkg/crd \
-  example:"$API_VERSION" \
+  example:"${API_VERSIONS// /,}" \
   --go-header-file hack/boilerplate.go.txt \
   --output-base "./pkg/crd/"
 
@@ -42,8 +42,10 @@ for modname in clientset informers listers; do
     rm -rf pkg/crd/example/$modname
     mv pkg/crd/github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/$modname pkg/crd/example/
 done
-rm -f pkg/crd/example/"$API_VERSION"/zz_generated.deepcopy.go
-mv pkg/crd/github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/"$API_VERSION"/zz_generated.deepcopy.go pkg/crd/example/"$API_VERSION"/
+for version in $API_VERSIONS; do
+    rm -f pkg/crd/example/"$version"/zz_generated.deepcopy.go
+    mv pkg/crd/github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/"$version"/zz_generated.deepcopy.go pkg/crd/example/"$version"/
+done
 
 # cleanup empty dir after moving sole subdir
 rm -r pkg/crd/github.com
@@ -0,0 +1,1086 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	resourcev1alpha1 "k8s.io/api/resource/v1alpha1"
+	coreclientset "k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	resourcelisters "k8s.io/client-go/listers/resource/v1alpha1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/dynamic-resource-allocation/controller"
+	"k8s.io/klog/v2"
+
+	myclientset "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/clientset/versioned"
+	mylisters "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/listers/example/v1alpha"
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+	driverVersion "github.com/kubernetes-sigs/dra-example-driver/pkg/version"
+)
+
+const (
+	minMemory = 8
+
+	// how long our own MAS writes shadow the informer cache until the watch catches up
+	masMutationCacheTTL = time.Minute
+
+	// index of the ResourceClaim informer to look claims up by UID
+	claimUIDIndex = "uid"
+
+	// index of the MAS informer to look up the MAS a claim is allocated on
+	masClaimUIDIndex = "claimUID"
+)
+
+type Driver struct {
+	lock                 *PerNodeMutex
+	namespace            string
+	clientset            myclientset.Interface
+	masLister            mylisters.MydeviceAllocationStateNamespaceLister
+	masCache             cache.MutationCache
+	claimIndexer         cache.Indexer
+	podLister            corelisters.PodLister
+	classLister          resourcelisters.ResourceClassLister
+	coreclient           coreclientset.Interface
+	quotaLister          mylisters.MydeviceQuotaLister
+	quotaLock            *PerNodeMutex // per namespace
+	recorder             record.EventRecorder
+	PendingClaimRequests *PerNodeClaimRequests
+}
+
+// Config is what the driver takes from the command line and environment
+type Config struct {
+	// namespace of the MydeviceAllocationState objects
+	Namespace              string
+	Clientset              myclientset.Interface
+	CoreClient             coreclientset.Interface
+	PendingClaimRequestTTL time.Duration
+}
+
+type onSuccessCallback func()
+
+var (
+	errInsufficientResources = fmt.Errorf("insufficient resources")
+	errMASNotReady           = fmt.Errorf("MydeviceAllocationState is not ready")
+	errIncompleteClaimGroup  = fmt.Errorf("pending requests of the claims of the pod are incomplete")
+)
+
+var _ controller.Driver = (*Driver)(nil)
+
+func NewDriver(config *Config, recorder record.EventRecorder, informerFactories *Informers) (*Driver, error) {
+	klog.V(5).Infof("Creating new driver")
+
+	driverVersion.PrintDriverVersion()
+
+	masInformer := informerFactories.Example.Dra().V1alpha().MydeviceAllocationStates()
+	err := masInformer.Informer().AddIndexers(cache.Indexers{masClaimUIDIndex: masClaimUIDIndexFunc})
+	if err != nil {
+		return nil, fmt.Errorf("add MydeviceAllocationState claim UID index: %v", err)
+	}
+
+	// same informer as used by the DRA controller, indexers must be added before it is started
+	claimInformer := informerFactories.Core.Resource().V1alpha1().ResourceClaims().Informer()
+	err = claimInformer.AddIndexers(cache.Indexers{claimUIDIndex: claimUIDIndexFunc})
+	if err != nil {
+		return nil, fmt.Errorf("add ResourceClaim UID index: %v", err)
+	}
+
+	return &Driver{
+		lock:                 NewPerNodeMutex(),
+		namespace:            config.Namespace,
+		clientset:            config.Clientset,
+		masLister:            masInformer.Lister().MydeviceAllocationStates(config.Namespace),
+		masCache:             cache.NewIntegerResourceVersionMutationCache(masInformer.Informer().GetStore(), masInformer.Informer().GetIndexer(), masMutationCacheTTL, true),
+		claimIndexer:         claimInformer.GetIndexer(),
+		podLister:            informerFactories.Core.Core().V1().Pods().Lister(),
+		classLister:          informerFactories.Core.Resource().V1alpha1().ResourceClasses().Lister(),
+		coreclient:           config.CoreClient,
+		quotaLister:          informerFactories.ExampleAllNamespaces.Dra().V1alpha().MydeviceQuotas().Lister(),
+		quotaLock:            NewPerNodeMutex(),
+		recorder:             recorder,
+		PendingClaimRequests: NewPerNodeClaimRequests(config.PendingClaimRequestTTL),
+	}, nil
+}
+
+func claimUIDIndexFunc(obj interface{}) ([]string, error) {
+	claim, ok := obj.(*resourcev1alpha1.ResourceClaim)
+	if !ok {
+		return nil, nil
+	}
+	return []string{string(claim.UID)}, nil
+}
+
+func masClaimUIDIndexFunc(obj interface{}) ([]string, error) {
+	mas, ok := obj.(*v1alpha.MydeviceAllocationState)
+	if !ok {
+		return nil, nil
+	}
+	claimUIDs := []string{}
+	for claimUID := range mas.Spec.ResourceClaimAllocations {
+		claimUIDs = append(claimUIDs, claimUID)
+	}
+	return claimUIDs, nil
+}
+
+// Look up a ResourceClaim in the informer cache by its UID
+func (d *Driver) getClaimByUID(claimUID string) (*resourcev1alpha1.ResourceClaim, bool) {
+	objs, err := d.claimIndexer.ByIndex(claimUIDIndex, claimUID)
+	if err != nil || len(objs) == 0 {
+		return nil, false
+	}
+	claim, ok := objs[0].(*resourcev1alpha1.ResourceClaim)
+	return claim, ok
+}
+
+// Periodically drop pending claim requests that have expired, or whose claim
+// was deleted or has been allocated in the meantime.
+func (d *Driver) RunPendingClaimRequestsCollector(ctx context.Context, interval time.Duration) {
+	klog.V(3).Infof("Starting pending claim requests collector with interval %v", interval)
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		removed := d.PendingClaimRequests.Collect(d.pendingClaimStale)
+		if removed > 0 {
+			klog.V(3).Infof("Garbage-collected %v pending claim requests", removed)
+		}
+		d.prunePendingRequests()
+	}, interval)
+}
+
+// Pending requests of a claim are stale once it is deleted or allocated
+func (d *Driver) pendingClaimStale(claimUID string) bool {
+	claim, exists := d.getClaimByUID(claimUID)
+	if !exists {
+		klog.V(5).Infof("Dropping pending requests of deleted claim %v", claimUID)
+		return true
+	}
+	if claim.DeletionTimestamp != nil || claim.Status.Allocation != nil {
+		klog.V(5).Infof("Dropping pending requests of claim %v/%v, deleted or allocated", claim.Namespace, claim.Name)
+		return true
+	}
+	return false
+}
+
+// Read MAS from the informer cache, newer objects written by this driver take precedence.
+// Returned object is a copy which is safe to modify.
+func (d *Driver) getMAS(nodename string) (*mycrd.MydeviceAllocationState, error) {
+	obj, exists, err := d.masCache.GetByKey(d.namespace + "/" + nodename)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha.Resource("mydeviceallocationstates"), nodename)
+	}
+
+	cached, ok := obj.(*v1alpha.MydeviceAllocationState)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object in MAS cache: %T", obj)
+	}
+
+	return mycrd.NewMydeviceAllocationStateFromObject(cached, d.clientset), nil
+}
+
+func (d *Driver) listMASNames() ([]string, error) {
+	masnames := []string{}
+
+	mass, err := d.masLister.List(labels.Everything())
+	if err != nil {
+		return masnames, err
+	}
+
+	for _, mas := range mass {
+		masnames = append(masnames, mas.Name)
+	}
+	return masnames, nil
+}
+
+// Write MAS spec to the API server and remember the result until the informer catches up
+func (d *Driver) updateMAS(mas *mycrd.MydeviceAllocationState) error {
+	err := mas.Update(&mas.Spec)
+	if err != nil {
+		return err
+	}
+
+	d.masCache.Mutation(mas.MydeviceAllocationState.DeepCopy())
+	return nil
+}
+
+// Apply the change to MAS and write it. The kubelet plugin writes the same object,
+// so on conflict the latest MAS is fetched from the API server and the change,
+// including any availability checks it does, is applied again.
+func (d *Driver) updateMASWithRetry(mas *mycrd.MydeviceAllocationState, apply func(mas *mycrd.MydeviceAllocationState) error) error {
+	attempt := 0
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if attempt > 0 {
+			klog.V(3).Infof("Conflict updating MydeviceAllocationState %v, retrying", mas.Name)
+			err := mas.Get()
+			if err != nil {
+				return err
+			}
+		}
+		attempt++
+
+		err := apply(mas)
+		if err != nil {
+			return err
+		}
+
+		return d.updateMAS(mas)
+	})
+}
+
+func (d Driver) GetClassParameters(ctx context.Context, class *resourcev1alpha1.ResourceClass) (interface{}, error) {
+	klog.V(5).InfoS("GetClassParameters called", "resource class", class.Name)
+
+	if class.ParametersRef == nil {
+		return mycrd.DefaultDeviceClassParametersSpec(), nil
+	}
+
+	if !mycrd.IsParametersAPIGroup(class.ParametersRef.APIGroup) {
+		return nil, fmt.Errorf(
+			"incorrect resource-class API group and version: %v, expected one of: %v",
+			class.ParametersRef.APIGroup,
+			mycrd.ParametersAPIGroups)
+	}
+
+	dc, err := d.clientset.DraV1alpha().MydeviceClassParameters().Get(ctx, class.ParametersRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not get DeviceClassParameters '%v': %v", class.ParametersRef.Name, err)
+	}
+
+	err = validateMydeviceClassParameters(&dc.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("could not validate DeviceClassParameters '%v': %v", class.ParametersRef.Name, err)
+	}
+
+	return &dc.Spec, nil
+}
+
+// Sanitize resource class parameters.
+func validateMydeviceClassParameters(classParams *mycrd.MydeviceClassParametersSpec) error {
+	klog.V(5).Infof("validateMydeviceClassParameters called")
+
+	return mycrd.ValidateMydeviceClassParametersSpec(classParams)
+}
+
+func (d Driver) GetClaimParameters(ctx context.Context, claim *resourcev1alpha1.ResourceClaim, class *resourcev1alpha1.ResourceClass, classParameters interface{}) (interface{}, error) {
+	klog.V(5).InfoS("GetClaimParameters called", "resource claim", claim.Namespace+"/"+claim.Name)
+	if claim.Spec.ParametersRef == nil {
+		return mycrd.DefaultMydeviceClaimParametersSpec(), nil
+	}
+
+	if !mycrd.IsParametersAPIGroup(claim.Spec.ParametersRef.APIGroup) {
+		return nil, fmt.Errorf(
+			"incorrect claim spec parameter API group and version: %v, expected one of: %v",
+			claim.Spec.ParametersRef.APIGroup,
+			mycrd.ParametersAPIGroups)
+	}
+
+	if mycrd.MydeviceClaimParametersKind != claim.Spec.ParametersRef.Kind {
+		klog.Error("Unsupported ResourceClaimParametersRef Kind: %v", claim.Spec.ParametersRef.Kind)
+		return nil, fmt.Errorf("Unsupported ResourceClaim.ParametersRef Kind: %v", claim.Spec.ParametersRef.Kind)
+
+	}
+
+	gcp, err := d.clientset.DraV1alpha().MydeviceClaimParameters(claim.Namespace).Get(ctx, claim.Spec.ParametersRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not get MydeviceClaimParameters '%v' in namespace '%v': %v", claim.Spec.ParametersRef.Name, claim.Namespace, err)
+	}
+
+	err = validateMydeviceClaimParameters(&gcp.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("could not validate MydeviceClaimParameters '%v' in namespace '%v': %v", claim.Spec.ParametersRef.Name, claim.Namespace, err)
+	}
+
+	return &gcp.Spec, nil
+}
+
+// Sanitize resource request parameters.
+func validateMydeviceClaimParameters(claimParams *mycrd.MydeviceClaimParametersSpec) error {
+	klog.V(5).Infof("validateMydeviceClaimParameters called")
+
+	// same checks as in the admission webhook, in case it is not deployed
+	return mycrd.ValidateMydeviceClaimParametersSpec(claimParams)
+}
+
+func (d Driver) Allocate(
+	ctx context.Context,
+	claim *resourcev1alpha1.ResourceClaim,
+	claimParameters interface{},
+	class *resourcev1alpha1.ResourceClass,
+	classParameters interface{},
+	selectedNode string) (*resourcev1alpha1.AllocationResult, error) {
+	klog.V(5).InfoS("Allocate called", "resource claim", claim.Namespace+"/"+claim.Name, "selectedNode", selectedNode)
+
+	mode := allocationModeDelayed
+	if selectedNode == "" {
+		mode = allocationModeImmediate
+	}
+	defer observeDuration(allocationDuration, mode, time.Now())
+
+	if claimParamsSpec, ok := claimParameters.(*mycrd.MydeviceClaimParametersSpec); ok {
+		err := d.resolveClaimPriority(claim, claimParamsSpec, nil)
+		if err != nil {
+			allocationFailures.WithLabelValues(mode, failureReasonError).Inc()
+			return nil, fmt.Errorf("error resolving claim priority: %v", err)
+		}
+	}
+
+	// usage is counted from MAS, hold the namespace lock until the allocation is written
+	var headroom mycrd.MydeviceUsage
+	if d.namespaceHasQuota(claim.Namespace) {
+		d.quotaLock.Get(claim.Namespace).Lock()
+		defer d.quotaLock.Get(claim.Namespace).Unlock()
+
+		cas := []*controller.ClaimAllocation{{Claim: claim, ClaimParameters: claimParameters}}
+		// pod siblings are allocated together with the claim and count against the quota too
+		if selectedNode != "" {
+			if mas, err := d.getMAS(selectedNode); err == nil {
+				cas = append(cas, d.pendingSiblingClaimAllocations(mas, string(claim.UID), selectedNode)...)
+			}
+		}
+		exceeded, err := d.quotaExceeded(claim.Namespace, cas)
+		if err != nil {
+			allocationFailures.WithLabelValues(mode, failureReasonError).Inc()
+			return nil, fmt.Errorf("error checking MydeviceQuota: %v", err)
+		}
+		if len(exceeded) > 0 {
+			allocationFailures.WithLabelValues(mode, failureReasonQuotaExceeded).Inc()
+			return nil, fmt.Errorf("%v", quotaExceededReason(exceeded))
+		}
+
+		// count ranges get at most what is left of the quota
+		headroom, err = d.quotaHeadroom(claim.Namespace, claimUIDs(cas)...)
+		if err != nil {
+			allocationFailures.WithLabelValues(mode, failureReasonError).Inc()
+			return nil, fmt.Errorf("error checking MydeviceQuota: %v", err)
+		}
+		limitToHeadroom(cas, headroom)
+		claimParameters = cas[0].ClaimParameters
+
+		defer func() {
+			err := d.syncQuotaStatus(ctx, claim.Namespace)
+			if err != nil {
+				klog.Errorf("Error syncing MydeviceQuota status: %v", err)
+			}
+		}()
+	}
+
+	// immediate allocation with no pendingResourceClaims
+	if selectedNode == "" {
+		return d.allocateImmediateClaim(ctx, claim, claimParameters, class, classParameters)
+	}
+
+	return d.allocatePendingClaim(ctx, claim, claimParameters, class, classParameters, selectedNode, headroom)
+}
+
+func (d Driver) allocateImmediateClaim(
+	ctx context.Context,
+	claim *resourcev1alpha1.ResourceClaim,
+	claimParameters interface{},
+	class *resourcev1alpha1.ResourceClass,
+	classParameters interface{},
+) (*resourcev1alpha1.AllocationResult, error) {
+	klog.V(5).Infof("Allocating immediately")
+
+	masnames, err := d.listMASNames()
+	if err != nil {
+		allocationFailures.WithLabelValues(allocationModeImmediate, failureReasonError).Inc()
+		return nil, fmt.Errorf("error retrieving list of MydeviceAllocationState objects: %v", err)
+	}
+
+	// create claimAllocation
+	ca := controller.ClaimAllocation{
+		Claim:           claim,
+		ClaimParameters: claimParameters,
+		Class:           class,
+		ClassParameters: classParameters,
+	}
+	cas := []*controller.ClaimAllocation{&ca}
+
+	masnames = d.sortNodesByPolicy(masnames, classParameters)
+
+	// once claims are being preempted for it, the claim waits for their devices
+	// instead of going to, or preempting claims on, another node
+	claimUID := string(claim.UID)
+	if nodename, pinned := d.PendingClaimRequests.PreemptionNode(claimUID); pinned {
+		klog.V(5).Infof("Claim %v waits for preempted claims on node %v", claimUID, nodename)
+		masnames = []string{nodename}
+	}
+
+	for _, nodename := range masnames {
+		d.lock.Get(nodename).Lock()
+
+		klog.V(5).Infof("Fetching MAS item: %v", nodename)
+		mas, err := d.getMAS(nodename)
+		if err != nil {
+			d.lock.Get(nodename).Unlock()
+			klog.Errorf("error retrieving MAS CRD for node %v: %v", nodename, err)
+			continue
+		}
+
+		claimParamsSpec := claimParameters.(*mycrd.MydeviceClaimParametersSpec)
+
+		err = d.updateMASWithRetry(mas, func(mas *mycrd.MydeviceAllocationState) error {
+			allocated := d.selectPotentialDevices(mas, cas)
+			klog.V(5).Infof("Allocated: %v", allocated)
+
+			if !mycrd.CountSatisfied(claimParamsSpec, len(allocated[claimUID].Mydevices)) {
+				return errInsufficientResources
+			}
+
+			klog.V(5).Infof("Allocated as much as requested, processing devices")
+
+			if mas.Spec.ResourceClaimRequests == nil {
+				mas.Spec.ResourceClaimRequests = make(map[string]mycrd.RequestedMydevices)
+			}
+			mas.Spec.ResourceClaimRequests[claimUID] = allocated[claimUID]
+
+			mas.MakeResourceClaimAllocation(claimUID)
+			return nil
+		})
+		if err == errInsufficientResources {
+			d.lock.Get(nodename).Unlock()
+			klog.V(3).Infof("Requested amount does not match allocated, skipping node %v", nodename)
+			continue // next node
+		}
+		if err != nil {
+			d.lock.Get(nodename).Unlock()
+			klog.Errorf("Could not update MydeviceAllocationState %v. Error: %+v", mas.Name, err)
+			allocationFailures.WithLabelValues(allocationModeImmediate, updateFailureReason(err)).Inc()
+			return nil, fmt.Errorf("error updating MydeviceAllocationState CRD: %v", err)
+		}
+
+		d.lock.Get(nodename).Unlock()
+		d.PendingClaimRequests.Remove(claimUID)
+
+		// first successfull allocation should suffice
+		d.recordAllocated(claim, nodename, mas.Spec.ResourceClaimAllocations[claimUID])
+		return buildAllocationResult(nodename, true, mas.Spec.ResourceClaimAllocations[claimUID])
+	}
+
+	// no node has enough free devices, make room by preempting lower priority claims
+	if nodename, plan := d.bestPreemptionNode(masnames, cas); plan != nil {
+		allocationFailures.WithLabelValues(allocationModeImmediate, failureReasonPreempting).Inc()
+		// remember the node, the retry must not pick another one while the victims go away
+		d.PendingClaimRequests.Set(claimUID, nodename, plan.allocated[claimUID])
+		d.PendingClaimRequests.SetVictims(claimUID, nodename, plan.victims)
+		err := d.preempt(ctx, claim, nodename, plan.victims)
+		if err != nil {
+			return nil, fmt.Errorf("error preempting claims on node '%v': %v", nodename, err)
+		}
+		return nil, fmt.Errorf("waiting for %d preempted claims to be deallocated from node '%v'", len(plan.victims), nodename)
+	}
+
+	// preempting on the chosen node does not help anymore, start over on all nodes
+	d.PendingClaimRequests.Remove(claimUID)
+
+	klog.V(3).InfoS("Could not immediately allocate", "resource claim", claim.Namespace+"/"+claim.Name)
+	allocationFailures.WithLabelValues(allocationModeImmediate, failureReasonNoNode).Inc()
+	return nil, fmt.Errorf("no suitable node found")
+}
+
+func (d Driver) allocatePendingClaim(
+	ctx context.Context,
+	claim *resourcev1alpha1.ResourceClaim,
+	claimParameters interface{},
+	class *resourcev1alpha1.ResourceClass,
+	classParameters interface{},
+	nodename string,
+	headroom mycrd.MydeviceUsage) (*resourcev1alpha1.AllocationResult, error) {
+	_, ok := claimParameters.(*mycrd.MydeviceClaimParametersSpec)
+	if !ok {
+		allocationFailures.WithLabelValues(allocationModeDelayed, failureReasonError).Inc()
+		return nil, fmt.Errorf("Unknown ResourceClaim.ParametersRef.Kind: %v", claim.Spec.ParametersRef.Kind)
+	}
+
+	d.lock.Get(nodename).Lock()
+	defer d.lock.Get(nodename).Unlock()
+
+	claimUID := string(claim.UID)
+
+	mas, err := d.getMAS(nodename)
+	if err != nil {
+		allocationFailures.WithLabelValues(allocationModeDelayed, failureReasonMASNotReady).Inc()
+		return nil, fmt.Errorf("Error retrieving MAS CRD for node %v: %v", nodename, err)
+	}
+
+	if mas.Status != mycrd.MydeviceAllocationStateStatusReady {
+		allocationFailures.WithLabelValues(allocationModeDelayed, failureReasonMASNotReady).Inc()
+		return nil, fmt.Errorf("MydeviceAllocationStateStatus: %v", mas.Status)
+	}
+
+	if mas.Spec.ResourceClaimRequests == nil {
+		mas.Spec.ResourceClaimRequests = make(map[string]mycrd.RequestedMydevices)
+	} else if _, exists := mas.Spec.ResourceClaimAllocations[claimUID]; exists {
+		klog.V(5).Infof("MAS already has ResourceClaimAllocation %v, building allocation result", claimUID)
+		return buildAllocationResult(nodename, true, mas.Spec.ResourceClaimAllocations[claimUID])
+	}
+
+	// Pick again for the whole pod if a sibling's request expired before the claim's
+	// own, or if other claims of the namespace got devices since UnsuitableNodes
+	// picked within the quota
+	if d.PendingClaimRequests.Exists(claimUID, nodename) {
+		group, err := d.pendingClaimGroup(mas, claimUID, nodename)
+		if err != nil || !withinHeadroom(d.pendingGroupUsage(group, nodename), headroom) {
+			klog.V(3).Infof("Pending requests for claim '%v' on node '%v' are incomplete or exceed MydeviceQuota, picking again", claimUID, nodename)
+			for _, groupClaimUID := range group {
+				d.PendingClaimRequests.Remove(groupClaimUID)
+			}
+		}
+	}
+
+	// Pending requests written to the MAS are restored after a restart or leader
+	// change. If they are missing anyway, e.g. because writing them failed, generate
+	// them again for the whole pod, the way UnsuitableNodes does, instead of waiting
+	// for the scheduler to retry.
+	if claim.Spec.AllocationMode != resourcev1alpha1.AllocationModeImmediate && !d.PendingClaimRequests.Exists(claimUID, nodename) {
+		klog.V(3).Infof("No allocation requests for claim '%v' on node '%v', generating them", claimUID, nodename)
+
+		mcas, err := d.podClaimAllocations(ctx, claim, claimParameters, class, classParameters)
+		if err != nil {
+			allocationFailures.WithLabelValues(allocationModeDelayed, failureReasonError).Inc()
+			return nil, fmt.Errorf("error getting claims of the pod using claim '%v': %v", claimUID, err)
+		}
+		limitToHeadroom(mcas, headroom)
+		// picking merges other pending requests into the MAS, which must not be written
+		picked := mycrd.NewMydeviceAllocationStateFromObject(mas.MydeviceAllocationState, nil)
+		if reason := d.pickPendingDevices(picked, mcas); reason != "" && d.pickPreemptionDevices(picked, mcas) == nil {
+			allocationFailures.WithLabelValues(allocationModeDelayed, failureReasonInsufficientDevices).Inc()
+			return nil, fmt.Errorf("Unable to allocate devices on node '%v': %v", nodename, reason)
+		}
+		// siblings picked again were not part of the quota check in Allocate
+		if !withinHeadroom(d.pendingGroupUsage(claimUIDs(mcas), nodename), headroom) {
+			allocationFailures.WithLabelValues(allocationModeDelayed, failureReasonQuotaExceeded).Inc()
+			return nil, fmt.Errorf("Unable to allocate devices on node '%v': claims of the pod exceed MydeviceQuota", nodename)
+		}
+	}
+
+	// the claim and its pod siblings are allocated together or not at all
+	var group []string
+	var onSuccess onSuccessCallback = func() {
+		for _, groupClaimUID := range group {
+			d.PendingClaimRequests.Remove(groupClaimUID)
+		}
+	}
+
+	// validated again against the latest MAS in case the update conflicts
+	err = d.updateMASWithRetry(mas, func(mas *mycrd.MydeviceAllocationState) error {
+		group = nil
+		if mas.Status != mycrd.MydeviceAllocationStateStatusReady {
+			klog.V(3).Infof("MydeviceAllocationStateStatus: %v", mas.Status)
+			return errMASNotReady
+		}
+
+		if _, exists := mas.Spec.ResourceClaimAllocations[claimUID]; exists {
+			klog.V(5).Infof("MAS already has ResourceClaimAllocation %v", claimUID)
+			return nil
+		}
+
+		// validate that there is still resource for all of them
+		pendingGroup, err := d.pendingClaimGroup(mas, claimUID, nodename)
+		if err != nil {
+			return err
+		}
+		if !d.enoughResourcesForPendingClaims(mas, pendingGroup, nodename) {
+			klog.V(5).Infof("Insufficient resource for claims %v on allocation", pendingGroup)
+			return errInsufficientResources
+		}
+
+		klog.V(5).Infof("Enough resources. Setting MAS ClaimRequests %v", pendingGroup)
+		if mas.Spec.ResourceClaimRequests == nil {
+			mas.Spec.ResourceClaimRequests = make(map[string]mycrd.RequestedMydevices)
+		}
+		for _, groupClaimUID := range pendingGroup {
+			mas.Spec.ResourceClaimRequests[groupClaimUID] = d.PendingClaimRequests.Get(groupClaimUID, nodename)
+			mas.MakeResourceClaimAllocation(groupClaimUID)
+		}
+		group = pendingGroup
+		return nil
+	})
+	if victims := d.PendingClaimRequests.GetVictims(claimUID, nodename); err == errInsufficientResources && len(victims) > 0 {
+		allocationFailures.WithLabelValues(allocationModeDelayed, failureReasonPreempting).Inc()
+		err = d.preempt(ctx, claim, nodename, victims)
+		if err != nil {
+			return nil, fmt.Errorf("error preempting claims on node '%v': %v", nodename, err)
+		}
+		return nil, fmt.Errorf("waiting for %d preempted claims to be deallocated from node '%v'", len(victims), nodename)
+	}
+	if err != nil {
+		allocationFailures.WithLabelValues(allocationModeDelayed, updateFailureReason(err)).Inc()
+	}
+	if err == errInsufficientResources {
+		return nil, fmt.Errorf("Unable to allocate devices on node '%v': Insufficient resources", nodename)
+	}
+	if err == errIncompleteClaimGroup {
+		// the retry picks devices for the whole pod again
+		return nil, fmt.Errorf("Unable to allocate devices on node '%v': %v", nodename, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error updating MydeviceAllocationState CRD: %v", err)
+	}
+
+	onSuccess()
+
+	d.recordAllocated(claim, nodename, mas.Spec.ResourceClaimAllocations[claimUID])
+	for _, groupClaimUID := range group {
+		if sibling, exists := d.getClaimByUID(groupClaimUID); exists && groupClaimUID != claimUID {
+			d.recordAllocated(sibling, nodename, mas.Spec.ResourceClaimAllocations[groupClaimUID])
+		}
+	}
+	return buildAllocationResult(nodename, true, mas.Spec.ResourceClaimAllocations[claimUID])
+}
+
+func (d Driver) Deallocate(ctx context.Context, claim *resourcev1alpha1.ResourceClaim) error {
+	klog.V(5).InfoS("Deallocate called", "resource claim", claim.Namespace+"/"+claim.Name)
+	defer observeDuration(deallocationDuration, allocationModeLabel(claim.Spec.AllocationMode), time.Now())
+
+	selectedNode := getSelectedNode(claim)
+	if selectedNode == "" {
+		return nil
+	}
+
+	err := d.deallocateOnNode(claim, selectedNode)
+	if err != nil {
+		return err
+	}
+
+	// the node lock is released by now, Allocate takes the quota lock first
+	if d.namespaceHasQuota(claim.Namespace) {
+		d.quotaLock.Get(claim.Namespace).Lock()
+		defer d.quotaLock.Get(claim.Namespace).Unlock()
+		err = d.syncQuotaStatus(ctx, claim.Namespace)
+		if err != nil {
+			klog.Errorf("Error syncing MydeviceQuota status: %v", err)
+		}
+	}
+	return nil
+}
+
+// Remove the claim's devices from the MAS of the node, holding the node lock
+func (d *Driver) deallocateOnNode(claim *resourcev1alpha1.ResourceClaim, selectedNode string) error {
+	d.lock.Get(selectedNode).Lock()
+	defer d.lock.Get(selectedNode).Unlock()
+
+	mas, err := d.getMAS(selectedNode)
+	if err != nil {
+		return fmt.Errorf("error retrieving MAS CRD for node %v: %v", selectedNode, err)
+	}
+
+	claimUID := string(claim.UID)
+	devices, exists := mas.Spec.ResourceClaimRequests[claimUID]
+	if !exists {
+		klog.Warning("Resource claim %v does not exist internally in resource driver")
+		return nil
+	}
+	switch devices.Spec.Type {
+	case mycrd.MydeviceType0:
+		d.PendingClaimRequests.Remove(claimUID)
+	default:
+		klog.Errorf("Unknown requested devices type: %v", devices.Spec.Type)
+		err = fmt.Errorf("unknown requested device type: %v", devices.Spec.Type)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to deallocate devices '%v': %v", devices, err)
+	}
+
+	allocatedDevices := mas.Spec.ResourceClaimAllocations[claimUID]
+	err = d.updateMASWithRetry(mas, func(mas *mycrd.MydeviceAllocationState) error {
+		if mas.Spec.ResourceClaimRequests != nil {
+			delete(mas.Spec.ResourceClaimRequests, claimUID)
+		}
+		if mas.Spec.ResourceClaimAllocations != nil {
+			delete(mas.Spec.ResourceClaimAllocations, claimUID)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error updating MydeviceAllocationState CRD: %v", err)
+	}
+
+	d.recordDeallocated(claim, selectedNode, allocatedDevices)
+	return nil
+}
+
+// Unsuitable nodes call chain
+// mark nodes that do not suit request into .UnsuitableNodes and populate d.PendingClaimAllocations
+func (d Driver) UnsuitableNodes(ctx context.Context, pod *corev1.Pod, cas []*controller.ClaimAllocation, potentialNodes []string) error {
+	_, err := d.UnsuitableNodeReasons(ctx, pod, cas, potentialNodes)
+	return err
+}
+
+// UnsuitableNodeReasons is UnsuitableNodes that also returns why each unsuitable node was rejected
+func (d Driver) UnsuitableNodeReasons(ctx context.Context, pod *corev1.Pod, cas []*controller.ClaimAllocation, potentialNodes []string) (map[string]string, error) {
+	klog.V(5).InfoS("UnsuitableNodes called", "cas length", len(cas))
+
+	reasons := unsuitableReasons{}
+	nodeReasons := make(map[string]string)
+	defer d.recordUnsuitableNodes(pod, cas, reasons, len(potentialNodes))
+
+	for _, ca := range cas {
+		if claimParamsSpec, ok := ca.ClaimParameters.(*mycrd.MydeviceClaimParametersSpec); ok {
+			err := d.resolveClaimPriority(ca.Claim, claimParamsSpec, pod)
+			if err != nil {
+				return nil, fmt.Errorf("error resolving claim priority: %v", err)
+			}
+		}
+	}
+
+	exceeded, err := d.quotaExceeded(pod.Namespace, cas)
+	if err != nil {
+		return nil, fmt.Errorf("error checking MydeviceQuota: %v", err)
+	}
+	if len(exceeded) > 0 {
+		klog.V(3).Infof("Pod %v/%v exceeds MydeviceQuota: %v", pod.Namespace, pod.Name, exceeded)
+		unsuitableNodes.WithLabelValues(failureReasonQuotaExceeded).Add(float64(len(potentialNodes)))
+		for _, ca := range cas {
+			ca.UnsuitableNodes = append(ca.UnsuitableNodes, potentialNodes...)
+			ca.UnsuitableNodes = unique(ca.UnsuitableNodes)
+		}
+		for _, node := range potentialNodes {
+			nodeReasons[node] = quotaExceededReason(exceeded)
+		}
+		reasons[quotaExceededReason(exceeded)] = len(potentialNodes)
+		return nodeReasons, nil
+	}
+
+	// count ranges get at most what is left of the quota
+	headroom, err := d.quotaHeadroom(pod.Namespace, claimUIDs(cas)...)
+	if err != nil {
+		return nil, fmt.Errorf("error checking MydeviceQuota: %v", err)
+	}
+	limitToHeadroom(cas, headroom)
+
+	for _, node := range potentialNodes {
+		klog.V(5).InfoS("UnsuitableNodes processing", "node", node)
+		reason, err := d.unsuitableNode(cas, node)
+		if err != nil {
+			return nil, fmt.Errorf("error checking if node '%v' is unsuitable: %v", node, err)
+		}
+		if reason != "" {
+			nodeReasons[node] = reason
+		}
+	}
+
+	// only preempt if the pod does not fit anywhere otherwise
+	if len(potentialNodes) > 0 && len(nodeReasons) == len(potentialNodes) {
+		for _, node := range potentialNodes {
+			if nodeReasons[node] == unsuitableReasonMASNotReady || !d.preemptibleNode(cas, node) {
+				continue
+			}
+			delete(nodeReasons, node)
+			for _, ca := range cas {
+				ca.UnsuitableNodes = removeString(ca.UnsuitableNodes, node)
+			}
+		}
+	}
+
+	for _, node := range potentialNodes {
+		if _, unsuitable := nodeReasons[node]; !unsuitable {
+			d.writePendingRequests(node, cas)
+		}
+	}
+
+	for _, reason := range nodeReasons {
+		reasons[reason]++
+	}
+
+	// remove duplicates from UnsuitableNodes
+	for _, claimallocation := range cas {
+		claimallocation.UnsuitableNodes = unique(claimallocation.UnsuitableNodes)
+	}
+	return nodeReasons, nil
+}
+
+// Returns why the node is unsuitable, or empty string if it is suitable
+func (d Driver) unsuitableNode(allcas []*controller.ClaimAllocation, potentialNode string) (string, error) {
+	d.lock.Get(potentialNode).Lock()
+	defer d.lock.Get(potentialNode).Unlock()
+
+	klog.V(5).InfoS("Getting MydeviceAllocationState", "node", potentialNode, "namespace", d.namespace)
+	mas, err := d.getMAS(potentialNode)
+	if err != nil || mas.Status != mycrd.MydeviceAllocationStateStatusReady {
+		klog.V(3).Infof("Could not get allocation state %v or it is not ready", potentialNode)
+		unsuitableNodes.WithLabelValues(failureReasonMASNotReady).Inc()
+		for _, ca := range allcas {
+			klog.V(5).Infof("Adding node %v to unsuitable nodes for CA %v", potentialNode, ca)
+			ca.UnsuitableNodes = append(ca.UnsuitableNodes, potentialNode)
+		}
+		return unsuitableReasonMASNotReady, nil
+	}
+
+	klog.V(5).Infof("MAS status OK")
+
+	if mas.Spec.ResourceClaimRequests == nil {
+		klog.V(5).Infof("Creating blank map for claim requests")
+		mas.Spec.ResourceClaimRequests = make(map[string]mycrd.RequestedMydevices)
+	}
+
+	filteredCAs := []*controller.ClaimAllocation{}
+
+	for _, ca := range allcas {
+		if _, ok := ca.ClaimParameters.(*mycrd.MydeviceClaimParametersSpec); ok {
+			filteredCAs = append(filteredCAs, ca)
+		} else {
+			klog.V(3).Infof("Unsupported claim parameters type %T", ca.ClaimParameters)
+		}
+	}
+
+	reason, err := d.unsuitableMydeviceNode(mas, filteredCAs, allcas)
+	if err != nil {
+		return "", fmt.Errorf("error processing '%v': %v", mycrd.MydeviceClaimParametersKind, err)
+	}
+
+	return reason, nil
+}
+
+func (d *Driver) unsuitableMydeviceNode(
+	mas *mycrd.MydeviceAllocationState,
+	mcas []*controller.ClaimAllocation,
+	allcas []*controller.ClaimAllocation) (string, error) {
+	klog.V(5).Infof("unsuitableMydeviceNode called")
+
+	reason := d.pickPendingDevices(mas, mcas)
+	if reason != "" {
+		klog.V(3).Infof("Requested number of devices does not match allocated, skipping node")
+		unsuitableNodes.WithLabelValues(failureReasonInsufficientDevices).Inc()
+		for _, ca := range allcas {
+			ca.UnsuitableNodes = append(ca.UnsuitableNodes, mas.Name)
+		}
+		return reason, nil
+	}
+
+	klog.V(5).Info("Leaving unsuitableMydeviceNode")
+	return "", nil
+}
+
+// Pick devices on the node for all claims of a pod and remember them as pending
+// requests of one group. The node lock must be held. Returns why the claims do
+// not fit, or empty string if they do.
+func (d *Driver) pickPendingDevices(mas *mycrd.MydeviceAllocationState, mcas []*controller.ClaimAllocation) string {
+	if mas.Spec.ResourceClaimRequests == nil {
+		mas.Spec.ResourceClaimRequests = make(map[string]mycrd.RequestedMydevices)
+	}
+
+	// remove pending claim requests that are in CRD already
+	// Add pending claim requests to CRD
+	d.PendingClaimRequests.CleanupNode(mas)
+	allocated := d.selectPotentialDevices(mas, mcas)
+	klog.V(5).Infof("Allocated: %v", allocated)
+	for _, ca := range mcas {
+		claimUID := string(ca.Claim.UID)
+		claimParamsSpec := ca.ClaimParameters.(*mycrd.MydeviceClaimParametersSpec)
+
+		if !mycrd.CountSatisfied(claimParamsSpec, len(allocated[claimUID].Mydevices)) {
+			reason := insufficientDevicesReason(claimParamsSpec, len(allocated[claimUID].Mydevices))
+			if len(mcas) > 1 {
+				reason = fmt.Sprintf("claim %v: %v", ca.Claim.Name, reason)
+			}
+			return reason
+		}
+	}
+
+	klog.V(5).Infof("Allocated as many devices as requested, processing devices")
+	for _, ca := range mcas {
+		claimUID := string(ca.Claim.UID)
+		d.PendingClaimRequests.Set(claimUID, mas.Name, allocated[claimUID])
+		mas.Spec.ResourceClaimRequests[claimUID] = allocated[claimUID]
+	}
+	d.setPendingClaimGroup(mas.Name, mcas)
+	return ""
+}
+
+// Allocate Mydevices out of available for all claim allocations or fail
+func (d *Driver) selectPotentialDevices(
+	mas *mycrd.MydeviceAllocationState,
+	mcas []*controller.ClaimAllocation) map[string]mycrd.RequestedMydevices {
+	klog.V(5).Infof("selectPotentialDevices called")
+
+	available := mas.Available()
+	consumers := mas.Consumers()
+	newlyAllocated := make(map[string]mycrd.RequestedMydevices)
+
+	for _, ca := range mcas {
+		claimUID := string(ca.Claim.UID)
+		claimParamsSpec := ca.ClaimParameters.(*mycrd.MydeviceClaimParametersSpec)
+		minCount, maxCount := mycrd.CountRange(claimParamsSpec)
+
+		// recalculating is cheaper than rescheduling, always recalculate or validate
+		if _, exists := mas.Spec.ResourceClaimRequests[claimUID]; exists {
+			klog.V(5).Infof("Found existing MAS ClaimRequest, validating")
+
+			// a smaller pick of a count range may grow if more devices are free now
+			reusePending := len(mas.Spec.ResourceClaimRequests[claimUID].Mydevices) == maxCount
+			for _, allocatedDevice := range mas.Spec.ResourceClaimRequests[claimUID].Mydevices {
+				_, exists := available[allocatedDevice.UID]
+				if !exists || !consumers.CanAdd(allocatedDevice.UID, allocatedDevice.MaxSharers) {
+					reusePending = false
+					break
+				}
+			}
+
+			if reusePending {
+				klog.V(5).Infof("Reusing pending ClaimRequest allocation %v", claimUID)
+				for _, allocatedDevice := range mas.Spec.ResourceClaimRequests[claimUID].Mydevices {
+					consumers.Add(allocatedDevice.UID, allocatedDevice.MaxSharers)
+				}
+				newlyAllocated[claimUID] = mycrd.RequestedMydevices{
+					Spec:           *claimParamsSpec,
+					Mydevices:      mas.Spec.ResourceClaimRequests[claimUID].Mydevices,
+					AllocatedCount: maxCount,
+				}
+				continue
+			}
+		}
+
+		classMaxSharers := 0
+		if classParamsSpec, ok := ca.ClassParameters.(*mycrd.MydeviceClassParametersSpec); ok && classParamsSpec != nil {
+			classMaxSharers = classParamsSpec.MaxSharers
+		}
+
+		// each device is picked at most once per claim, even if it is shareable
+		var candidates []*mycrd.AllocatableMydevice
+		for _, device := range available {
+			maxSharers := mycrd.EffectiveMaxSharers(device, classMaxSharers)
+			if deviceMatchesClaim(device, claimParamsSpec, ca.ClassParameters) && consumers.CanAdd(device.UID, maxSharers) {
+				candidates = append(candidates, device)
+			}
+		}
+
+		var devices []mycrd.RequestedMydevice
+		for _, device := range pickDevicesInRange(candidates, minCount, maxCount, claimParamsSpec.Topology) {
+			maxSharers := mycrd.EffectiveMaxSharers(device, classMaxSharers)
+			devices = append(devices, mycrd.RequestedMydevice{
+				UID:        device.UID,
+				MaxSharers: maxSharers,
+			})
+			consumers.Add(device.UID, maxSharers)
+		}
+
+		newlyAllocated[claimUID] = mycrd.RequestedMydevices{
+			Spec:           *claimParamsSpec,
+			Mydevices:      devices,
+			AllocatedCount: len(devices),
+		}
+	}
+
+	return newlyAllocated
+}
+
+// Device must be of the requested type, match claim attribute selector and
+// resource class selectors
+func deviceMatchesClaim(
+	device *mycrd.AllocatableMydevice,
+	claimParamsSpec *mycrd.MydeviceClaimParametersSpec,
+	classParameters interface{}) bool {
+	classParamsSpec, _ := classParameters.(*mycrd.MydeviceClassParametersSpec)
+	return mycrd.DeviceMatchesClaim(device, claimParamsSpec, classParamsSpec)
+}
+
+// ensure claims still fit into available devices, all of them together
+func (d *Driver) enoughResourcesForPendingClaims(
+	mas *mycrd.MydeviceAllocationState,
+	pendingClaimUIDs []string,
+	selectedNode string) bool {
+	klog.V(5).Infof("enoughResourcesForPendingClaims called for claims %v", pendingClaimUIDs)
+
+	available := mas.Available()
+	consumers := mas.Consumers()
+	for _, pendingClaimUID := range pendingClaimUIDs {
+		pendingClaim := d.PendingClaimRequests.Get(pendingClaimUID, selectedNode)
+		for _, device := range pendingClaim.Mydevices {
+			if _, exists := available[device.UID]; !exists || !consumers.CanAdd(device.UID, device.MaxSharers) {
+				klog.Errorf("Device %v from pending claim %v is not available", device.UID, pendingClaimUID)
+				return false
+			}
+			consumers.Add(device.UID, device.MaxSharers)
+		}
+	}
+
+	return true
+}
+
+func buildAllocationResult(selectedNode string, shared bool, devices mycrd.AllocatedMydevices) (*resourcev1alpha1.AllocationResult, error) {
+	nodeSelector := &corev1.NodeSelector{
+		NodeSelectorTerms: []corev1.NodeSelectorTerm{
+			{
+				MatchFields: []corev1.NodeSelectorRequirement{
+					{
+						Key:      "metadata.name",
+						Operator: "In",
+						Values:   []string{selectedNode},
+					},
+				},
+			},
+		},
+	}
+
+	resourceHandle, err := mycrd.EncodeResourceHandle(devices)
+	if err != nil {
+		return nil, err
+	}
+
+	allocation := &resourcev1alpha1.AllocationResult{
+		AvailableOnNodes: nodeSelector,
+		ResourceHandle:   resourceHandle,
+	}
+	return allocation, nil
+}
+
+func getSelectedNode(claim *resourcev1alpha1.ResourceClaim) string {
+	if claim.Status.Allocation == nil {
+		return ""
+	}
+
+	if claim.Status.Allocation.AvailableOnNodes == nil {
+		return ""
+	}
+
+	return claim.Status.Allocation.AvailableOnNodes.NodeSelectorTerms[0].MatchFields[0].Values[0]
+}
+
+func removeString(s []string, str string) []string {
+	var filtered []string
+	for _, item := range s {
+		if item != str {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+func unique(s []string) []string {
+	set := make(map[string]bool)
+	var filtered []string
+	for _, str := range s {
+		if _, exists := set[str]; !exists {
+			set[str] = true
+			filtered = append(filtered, str)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,369 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	resourcev1alpha1 "k8s.io/api/resource/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	corefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/dynamic-resource-allocation/controller"
+
+	myfake "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/clientset/versioned/fake"
+	myinformers "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/informers/externalversions"
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+const (
+	testNamespace      = "dra-example-driver"
+	testClaimNamespace = "default"
+	testClassName      = "mydevice"
+)
+
+// testCluster holds fake clientsets that any number of drivers can share,
+// like controller replicas taking over from each other
+type testCluster struct {
+	coreclient      *corefake.Clientset
+	exampleclient   *myfake.Clientset
+	resourceVersion int
+}
+
+// newTestCluster creates fake clientsets with given core and example objects
+func newTestCluster(t *testing.T, objects ...runtime.Object) *testCluster {
+	c := &testCluster{
+		coreclient:    corefake.NewSimpleClientset(),
+		exampleclient: myfake.NewSimpleClientset(),
+	}
+	// the driver prefers its own MAS writes over the informer cache by resource version,
+	// which the fake clientset does not maintain
+	c.exampleclient.PrependReactor("*", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action, ok := action.(interface{ GetObject() runtime.Object }); ok {
+			c.setResourceVersion(action.GetObject())
+		}
+		return false, nil, nil
+	})
+
+	for _, obj := range objects {
+		if err := c.add(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return c
+}
+
+// The fake clientset guesses resources from kinds and gets the plural of the
+// parameters kinds wrong, so example objects are added with their resource
+func (c *testCluster) add(obj runtime.Object) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+
+	var resource string
+	switch obj.(type) {
+	case *v1alpha.MydeviceAllocationState:
+		resource = "mydeviceallocationstates"
+	case *v1alpha.MydeviceClassParameters:
+		resource = "mydeviceclassparameters"
+	case *v1alpha.MydeviceClaimParameters:
+		resource = "mydeviceclaimparameters"
+	case *v1alpha.MydeviceQuota:
+		resource = "mydevicequotas"
+	default:
+		return c.coreclient.Tracker().Add(obj)
+	}
+
+	c.setResourceVersion(obj)
+	err = c.exampleclient.Tracker().Create(v1alpha.SchemeGroupVersion.WithResource(resource), obj, accessor.GetNamespace())
+	if err != nil {
+		return fmt.Errorf("error adding %v: %v", resource, err)
+	}
+	return nil
+}
+
+func (c *testCluster) setResourceVersion(obj runtime.Object) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+	c.resourceVersion++
+	accessor.SetResourceVersion(strconv.Itoa(c.resourceVersion))
+}
+
+// newDriver creates a driver with its own informers, started and synced
+func (c *testCluster) newDriver(t *testing.T) *Driver {
+	informerFactories := &Informers{
+		Core:                 informers.NewSharedInformerFactory(c.coreclient, 0 /* resync period */),
+		Example:              myinformers.NewSharedInformerFactoryWithOptions(c.exampleclient, 0 /* resync period */, myinformers.WithNamespace(testNamespace)),
+		ExampleAllNamespaces: myinformers.NewSharedInformerFactory(c.exampleclient, 0 /* resync period */),
+	}
+	config := &Config{
+		Namespace:              testNamespace,
+		Clientset:              c.exampleclient,
+		CoreClient:             c.coreclient,
+		PendingClaimRequestTTL: time.Minute,
+	}
+	// events are not of interest, a FakeRecorder without channel drops them
+	driver, err := NewDriver(config, &record.FakeRecorder{}, informerFactories)
+	if err != nil {
+		t.Fatalf("error creating driver: %v", err)
+	}
+
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+	informerFactories.Start(stopCh)
+	if err := informerFactories.WaitForCacheSync(stopCh); err != nil {
+		t.Fatal(err)
+	}
+	if err := driver.RestorePendingClaimRequests(); err != nil {
+		t.Fatal(err)
+	}
+	return driver
+}
+
+func (c *testCluster) getMAS(t *testing.T, node string) *v1alpha.MydeviceAllocationState {
+	mas, err := c.exampleclient.DraV1alpha().MydeviceAllocationStates(testNamespace).Get(context.TODO(), node, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error getting MAS %v: %v", node, err)
+	}
+	return mas
+}
+
+func testDevice(uid string) v1alpha.AllocatableMydevice {
+	return v1alpha.AllocatableMydevice{
+		UID:       uid,
+		Type:      mycrd.MydeviceType0,
+		CDIDevice: "example.com/mydevice=" + uid,
+	}
+}
+
+func testMAS(node string, devices ...v1alpha.AllocatableMydevice) *v1alpha.MydeviceAllocationState {
+	mas := &v1alpha.MydeviceAllocationState{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      node,
+			Namespace: testNamespace,
+		},
+		Spec: v1alpha.MydeviceAllocationStateSpec{
+			AllocatableMydevices: make(map[string]v1alpha.AllocatableMydevice),
+		},
+		Status: mycrd.MydeviceAllocationStateStatusReady,
+	}
+	for _, device := range devices {
+		mas.Spec.AllocatableMydevices[device.UID] = device
+	}
+	return mas
+}
+
+func testClaim(name string) *resourcev1alpha1.ResourceClaim {
+	return &resourcev1alpha1.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: testClaimNamespace,
+			UID:       types.UID(name + "-uid"),
+		},
+		Spec: resourcev1alpha1.ResourceClaimSpec{
+			ResourceClassName: testClassName,
+			AllocationMode:    resourcev1alpha1.AllocationModeImmediate,
+		},
+	}
+}
+
+// claim allocated once the scheduler picked a node for its pod
+func testDelayedClaim(name string) *resourcev1alpha1.ResourceClaim {
+	claim := testClaim(name)
+	claim.Spec.AllocationMode = resourcev1alpha1.AllocationModeWaitForFirstConsumer
+	return claim
+}
+
+func testPod(name string, claims ...*resourcev1alpha1.ResourceClaim) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: testClaimNamespace,
+			UID:       types.UID(name + "-uid"),
+		},
+	}
+	for _, claim := range claims {
+		claimName := claim.Name
+		pod.Spec.ResourceClaims = append(pod.Spec.ResourceClaims, corev1.PodResourceClaim{
+			Name:   claim.Name,
+			Source: corev1.ClaimSource{ResourceClaimName: &claimName},
+		})
+	}
+	return pod
+}
+
+// claim allocations as the DRA controller passes them to UnsuitableNodes
+func testClaimAllocations(claims ...*resourcev1alpha1.ResourceClaim) []*controller.ClaimAllocation {
+	cas := []*controller.ClaimAllocation{}
+	for _, claim := range claims {
+		cas = append(cas, &controller.ClaimAllocation{
+			Claim:           claim,
+			ClaimParameters: countSpec(1),
+			Class:           testClass(),
+			ClassParameters: classSpec(0),
+		})
+	}
+	return cas
+}
+
+func testClass() *resourcev1alpha1.ResourceClass {
+	return &resourcev1alpha1.ResourceClass{
+		ObjectMeta: metav1.ObjectMeta{Name: testClassName},
+		DriverName: mycrd.ApiGroupName,
+	}
+}
+
+func countSpec(count int) *mycrd.MydeviceClaimParametersSpec {
+	return &mycrd.MydeviceClaimParametersSpec{
+		Count: count,
+		Type:  mycrd.MydeviceType0,
+	}
+}
+
+func rangeSpec(minCount, maxCount int) *mycrd.MydeviceClaimParametersSpec {
+	return &mycrd.MydeviceClaimParametersSpec{
+		MinCount: minCount,
+		MaxCount: maxCount,
+		Type:     mycrd.MydeviceType0,
+	}
+}
+
+func classSpec(maxSharers int) *mycrd.MydeviceClassParametersSpec {
+	spec := mycrd.DefaultDeviceClassParametersSpec()
+	spec.MaxSharers = maxSharers
+	return spec
+}
+
+func TestAllocateSharedDevice(t *testing.T) {
+	testCases := []struct {
+		name             string
+		deviceMaxSharers int
+		classMaxSharers  int
+		expectedSharers  int
+	}{
+		{"defaults are exclusive", 0, 0, 1},
+		{"class shares device without limit", 0, 2, 2},
+		{"device limit caps class", 2, 3, 2},
+		{"exclusive device", 1, 3, 1},
+		{"device shares without class limit", 3, 0, 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			device := testDevice("dev0")
+			device.MaxSharers = tc.deviceMaxSharers
+			c := newTestCluster(t, testMAS("node1", device))
+			d := c.newDriver(t)
+
+			allocated := 0
+			for i := 0; i < tc.expectedSharers+1; i++ {
+				claim := testClaim(fmt.Sprintf("claim%d", i))
+				_, err := d.Allocate(context.TODO(), claim, countSpec(1), testClass(), classSpec(tc.classMaxSharers), "")
+				if err == nil {
+					allocated++
+				}
+			}
+			if allocated != tc.expectedSharers {
+				t.Fatalf("expected %d claims to share the device, got %d", tc.expectedSharers, allocated)
+			}
+
+			// every sharer records the same limit, so none of them caps the others
+			for claimUID, devices := range c.getMAS(t, "node1").Spec.ResourceClaimAllocations {
+				if len(devices) != 1 || devices[0].UID != "dev0" || devices[0].MaxSharers != tc.expectedSharers {
+					t.Errorf("unexpected allocation of claim %v: %+v", claimUID, devices)
+				}
+			}
+		})
+	}
+}
+
+func TestAllocateCountRange(t *testing.T) {
+	testCases := []struct {
+		name     string
+		devices  int
+		spec     *mycrd.MydeviceClaimParametersSpec
+		expected int // 0 if the claim does not fit
+	}{
+		{"maximum available", 5, rangeSpec(2, 4), 4},
+		{"as many as free", 3, rangeSpec(2, 4), 3},
+		{"minimum", 2, rangeSpec(2, 4), 2},
+		{"below minimum", 1, rangeSpec(2, 4), 0},
+		{"minCount defaults to 1", 1, rangeSpec(0, 4), 1},
+		{"exact count", 3, countSpec(2), 2},
+	}
+
+	for _, mode := range []resourcev1alpha1.AllocationMode{resourcev1alpha1.AllocationModeImmediate, resourcev1alpha1.AllocationModeWaitForFirstConsumer} {
+		for _, tc := range testCases {
+			t.Run(string(mode)+"/"+tc.name, func(t *testing.T) {
+				devices := []v1alpha.AllocatableMydevice{}
+				for i := 0; i < tc.devices; i++ {
+					devices = append(devices, testDevice(fmt.Sprintf("dev%d", i)))
+				}
+				claim := testClaim("claim")
+				claim.Spec.AllocationMode = mode
+				c := newTestCluster(t, testMAS("node1", devices...), testClass(), claim)
+				d := c.newDriver(t)
+
+				selectedNode := ""
+				if mode == resourcev1alpha1.AllocationModeWaitForFirstConsumer {
+					pod := testPod("pod", claim)
+					cas := []*controller.ClaimAllocation{{Claim: claim, ClaimParameters: tc.spec, Class: testClass(), ClassParameters: classSpec(0)}}
+					if err := d.UnsuitableNodes(context.TODO(), pod, cas, []string{"node1"}); err != nil {
+						t.Fatalf("UnsuitableNodes: %v", err)
+					}
+					if unsuitable := len(cas[0].UnsuitableNodes) > 0; unsuitable != (tc.expected == 0) {
+						t.Fatalf("expected node1 unsuitable: %v, got unsuitable nodes %v", tc.expected == 0, cas[0].UnsuitableNodes)
+					}
+					selectedNode = "node1"
+				}
+
+				result, err := d.Allocate(context.TODO(), claim, tc.spec, testClass(), classSpec(0), selectedNode)
+				if tc.expected == 0 {
+					if err == nil {
+						t.Fatalf("expected allocation to fail, got %+v", result)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Allocate: %v", err)
+				}
+
+				mas := c.getMAS(t, "node1")
+				if allocated := len(mas.Spec.ResourceClaimAllocations["claim-uid"]); allocated != tc.expected {
+					t.Errorf("expected %d devices, got %d", tc.expected, allocated)
+				}
+				if request := mas.Spec.ResourceClaimRequests["claim-uid"]; request.AllocatedCount != tc.expected {
+					t.Errorf("expected allocated count %d in MAS, got %d", tc.expected, request.AllocatedCount)
+				}
+			})
+		}
+	}
+}
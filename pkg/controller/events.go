@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	resourcev1alpha1 "k8s.io/api/resource/v1alpha1"
+	"k8s.io/dynamic-resource-allocation/controller"
+
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+// Event reasons
+const (
+	eventReasonUnsuitableNodes = "UnsuitableNodes"
+	eventReasonAllocated       = "Allocated"
+	eventReasonDeallocated     = "Deallocated"
+)
+
+// Reasons for a node to be unsuitable
+const (
+	unsuitableReasonMASNotReady = "MAS not ready"
+)
+
+// unsuitableReasons counts potential nodes by the reason they were found unsuitable
+type unsuitableReasons map[string]int
+
+// String summarizes the reasons, most common first, e.g.
+// "3 nodes: MAS not ready, 5 nodes: only 1 of 2 type0 devices free"
+func (r unsuitableReasons) String() string {
+	reasons := make([]string, 0, len(r))
+	for reason := range r {
+		reasons = append(reasons, reason)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if r[reasons[i]] != r[reasons[j]] {
+			return r[reasons[i]] > r[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+
+	parts := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		nodes := "nodes"
+		if r[reason] == 1 {
+			nodes = "node"
+		}
+		parts = append(parts, fmt.Sprintf("%d %v: %v", r[reason], nodes, reason))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func insufficientDevicesReason(claimParamsSpec *mycrd.MydeviceClaimParametersSpec, found int) string {
+	deviceType := claimParamsSpec.Type
+	if deviceType == "" {
+		deviceType = mycrd.MydeviceType0
+	}
+	minCount, _ := mycrd.CountRange(claimParamsSpec)
+	return fmt.Sprintf("only %d of %d %v devices free", found, minCount, deviceType)
+}
+
+// Explain on the pod and its claims why potential nodes were rejected
+func (d *Driver) recordUnsuitableNodes(pod *corev1.Pod, cas []*controller.ClaimAllocation, reasons unsuitableReasons, potentialNodes int) {
+	if len(reasons) == 0 {
+		return
+	}
+
+	unsuitable := 0
+	for _, count := range reasons {
+		unsuitable += count
+	}
+
+	eventType := corev1.EventTypeNormal
+	if unsuitable == potentialNodes {
+		eventType = corev1.EventTypeWarning
+	}
+
+	message := fmt.Sprintf("%d of %d potential nodes unsuitable: %v", unsuitable, potentialNodes, reasons)
+	d.recorder.Event(pod, eventType, eventReasonUnsuitableNodes, message)
+	for _, ca := range cas {
+		d.recorder.Event(ca.Claim, eventType, eventReasonUnsuitableNodes, message)
+	}
+}
+
+func (d *Driver) recordAllocated(claim *resourcev1alpha1.ResourceClaim, nodename string, devices mycrd.AllocatedMydevices) {
+	d.recorder.Eventf(claim, corev1.EventTypeNormal, eventReasonAllocated,
+		"Allocated %d devices on node %v: %v", len(devices), nodename, deviceUIDs(devices))
+}
+
+func (d *Driver) recordDeallocated(claim *resourcev1alpha1.ResourceClaim, nodename string, devices mycrd.AllocatedMydevices) {
+	d.recorder.Eventf(claim, corev1.EventTypeNormal, eventReasonDeallocated,
+		"Deallocated %d devices on node %v: %v", len(devices), nodename, deviceUIDs(devices))
+}
+
+func deviceUIDs(devices mycrd.AllocatedMydevices) string {
+	uids := make([]string, 0, len(devices))
+	for _, device := range devices {
+		uids = append(uids, device.UID)
+	}
+	return strings.Join(uids, ", ")
+}
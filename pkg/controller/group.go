@@ -0,0 +1,205 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	resourcev1alpha1 "k8s.io/api/resource/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/dynamic-resource-allocation/controller"
+	"k8s.io/klog/v2"
+
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+// The DRA controller calls Allocate for each claim of a pod separately. Claims
+// fitted on a node together by UnsuitableNodes form a group, and the first of
+// them to be allocated writes the allocations of the whole group into the MAS
+// at once. Allocate of the others then finds its allocation in place. If the
+// group does not fit anymore, no claim of it gets devices. If a claim of the
+// group lost its pending request, devices are picked for the whole pod again.
+
+// Remember which claims of a pod were fitted on the node together
+func (d *Driver) setPendingClaimGroup(node string, mcas []*controller.ClaimAllocation) {
+	if len(mcas) < 2 {
+		return
+	}
+
+	for _, ca := range mcas {
+		claimUID := string(ca.Claim.UID)
+		siblings := []string{}
+		for _, sibling := range mcas {
+			if sibling != ca {
+				siblings = append(siblings, string(sibling.Claim.UID))
+			}
+		}
+		d.PendingClaimRequests.SetSiblings(claimUID, node, siblings)
+	}
+}
+
+// Claim and those of its siblings that are not allocated yet, all of which have
+// a pending request on the node. Deleted siblings are left out. If a sibling has
+// no request anymore, e.g. because it expired before the claim's own, the
+// members found are returned together with errIncompleteClaimGroup.
+func (d *Driver) pendingClaimGroup(mas *mycrd.MydeviceAllocationState, claimUID, node string) ([]string, error) {
+	group := []string{claimUID}
+	var err error
+	for _, sibling := range d.PendingClaimRequests.GetSiblings(claimUID, node) {
+		if _, allocated := mas.Spec.ResourceClaimAllocations[sibling]; allocated {
+			continue
+		}
+		claim, exists := d.getClaimByUID(sibling)
+		if !exists || claim.DeletionTimestamp != nil || claim.Status.Allocation != nil {
+			continue
+		}
+		if !d.PendingClaimRequests.Exists(sibling, node) {
+			klog.V(3).Infof("No pending request for claim %v on node %v, which is allocated together with claim %v", sibling, node, claimUID)
+			err = errIncompleteClaimGroup
+			continue
+		}
+		group = append(group, sibling)
+	}
+	return group, err
+}
+
+// Claim allocations of the group members other than claimUID, for quota checks.
+// An incomplete group is picked again before it is allocated, see allocatePendingClaim.
+func (d *Driver) pendingSiblingClaimAllocations(mas *mycrd.MydeviceAllocationState, claimUID, node string) []*controller.ClaimAllocation {
+	cas := []*controller.ClaimAllocation{}
+	group, _ := d.pendingClaimGroup(mas, claimUID, node)
+	for _, sibling := range group[1:] {
+		claim, exists := d.getClaimByUID(sibling)
+		if !exists {
+			continue
+		}
+		request := d.PendingClaimRequests.Get(sibling, node)
+		cas = append(cas, &controller.ClaimAllocation{
+			Claim:           claim,
+			ClaimParameters: &request.Spec,
+		})
+	}
+	return cas
+}
+
+// Name of the ResourceClaim a pod refers to, claims generated from a template
+// are named after the pod
+func podClaimName(pod *corev1.Pod, podClaim corev1.PodResourceClaim) string {
+	if podClaim.Source.ResourceClaimName != nil {
+		return *podClaim.Source.ResourceClaimName
+	}
+	return pod.Name + "-" + podClaim.Name
+}
+
+// Pod the claim is being allocated for: the owner of a claim generated from a
+// template, otherwise an unscheduled pod in the namespace that uses the claim.
+// Returns nil if there is none.
+func (d *Driver) consumingPod(claim *resourcev1alpha1.ResourceClaim) (*corev1.Pod, error) {
+	if owner := metav1.GetControllerOf(claim); owner != nil && owner.APIVersion == "v1" && owner.Kind == "Pod" {
+		return d.ownerPod(claim)
+	}
+
+	pods, err := d.podLister.Pods(claim.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error listing pods in namespace %v: %v", claim.Namespace, err)
+	}
+	for _, pod := range pods {
+		if pod.Spec.NodeName != "" {
+			continue
+		}
+		for _, podClaim := range pod.Spec.ResourceClaims {
+			if podClaimName(pod, podClaim) == claim.Name {
+				return pod, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Claim allocations of the claim and of the other unallocated claims of this
+// driver that its pod uses, as UnsuitableNodes gets them from the DRA controller.
+// The claim comes first.
+func (d *Driver) podClaimAllocations(
+	ctx context.Context,
+	claim *resourcev1alpha1.ResourceClaim,
+	claimParameters interface{},
+	class *resourcev1alpha1.ResourceClass,
+	classParameters interface{}) ([]*controller.ClaimAllocation, error) {
+	mcas := []*controller.ClaimAllocation{{
+		Claim:           claim,
+		ClaimParameters: claimParameters,
+		Class:           class,
+		ClassParameters: classParameters,
+	}}
+
+	pod, err := d.consumingPod(claim)
+	if err != nil || pod == nil {
+		return mcas, err
+	}
+
+	for _, podClaim := range pod.Spec.ResourceClaims {
+		name := podClaimName(pod, podClaim)
+		if name == claim.Name {
+			continue
+		}
+
+		obj, exists, err := d.claimIndexer.GetByKey(claim.Namespace + "/" + name)
+		if err != nil {
+			return nil, fmt.Errorf("error getting claim %v/%v: %v", claim.Namespace, name, err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("claim %v/%v of pod %v not found", claim.Namespace, name, pod.Name)
+		}
+		sibling := obj.(*resourcev1alpha1.ResourceClaim)
+		if sibling.Status.Allocation != nil || sibling.DeletionTimestamp != nil {
+			continue
+		}
+
+		siblingClass, err := d.classLister.Get(sibling.Spec.ResourceClassName)
+		if err != nil {
+			return nil, fmt.Errorf("error getting resource class %v: %v", sibling.Spec.ResourceClassName, err)
+		}
+		if siblingClass.DriverName != mycrd.ApiGroupName {
+			continue
+		}
+		siblingClassParameters, err := d.GetClassParameters(ctx, siblingClass)
+		if err != nil {
+			return nil, err
+		}
+		siblingClaimParameters, err := d.GetClaimParameters(ctx, sibling, siblingClass, siblingClassParameters)
+		if err != nil {
+			return nil, err
+		}
+		if claimParamsSpec, ok := siblingClaimParameters.(*mycrd.MydeviceClaimParametersSpec); ok {
+			err := d.resolveClaimPriority(sibling, claimParamsSpec, pod)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		mcas = append(mcas, &controller.ClaimAllocation{
+			Claim:           sibling,
+			ClaimParameters: siblingClaimParameters,
+			Class:           siblingClass,
+			ClassParameters: siblingClassParameters,
+		})
+	}
+	return mcas, nil
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+)
+
+// Change the MAS as another writer would and wait for the driver to see it
+func (c *testCluster) updateMAS(t *testing.T, d *Driver, node string, mutate func(mas *v1alpha.MydeviceAllocationState)) {
+	t.Helper()
+	mas := c.getMAS(t, node)
+	mutate(mas)
+	updated, err := c.exampleclient.DraV1alpha().MydeviceAllocationStates(testNamespace).Update(context.TODO(), mas, metav1.UpdateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = wait.PollImmediate(10*time.Millisecond, 10*time.Second, func() (bool, error) {
+		current, err := d.getMAS(node)
+		if err != nil {
+			return false, err
+		}
+		return current.ResourceVersion == updated.ResourceVersion, nil
+	})
+	if err != nil {
+		t.Fatalf("update of MAS %v not seen by the driver: %v", node, err)
+	}
+}
+
+func TestPodClaimsAllocatedTogether(t *testing.T) {
+	claimA := testDelayedClaim("claim-a")
+	claimB := testDelayedClaim("claim-b")
+	pod := testPod("pod", claimA, claimB)
+	c := newTestCluster(t, testMAS("node1", testDevice("dev0"), testDevice("dev1"), testDevice("dev2")), testClass(), claimA, claimB, pod)
+	d := c.newDriver(t)
+
+	if err := d.UnsuitableNodes(context.TODO(), pod, testClaimAllocations(claimA, claimB), []string{"node1"}); err != nil {
+		t.Fatalf("UnsuitableNodes: %v", err)
+	}
+	if _, err := d.Allocate(context.TODO(), claimA, countSpec(1), testClass(), classSpec(0), "node1"); err != nil {
+		t.Fatalf("Allocate: %v", err)
+	}
+
+	// claim-b is allocated by Allocate of claim-a already
+	allocations := c.getMAS(t, "node1").Spec.ResourceClaimAllocations
+	a, b := allocations["claim-a-uid"], allocations["claim-b-uid"]
+	if len(a) != 1 || len(b) != 1 {
+		t.Fatalf("expected both claims allocated one device, got %v", allocations)
+	}
+	if a[0].UID == b[0].UID {
+		t.Errorf("claims of the pod got the same exclusive device %v", a[0].UID)
+	}
+	if d.PendingClaimRequests.Exists("claim-b-uid", "node1") {
+		t.Errorf("pending request of claim-b kept after allocation")
+	}
+
+	result, err := d.Allocate(context.TODO(), claimB, countSpec(1), testClass(), classSpec(0), "node1")
+	if err != nil {
+		t.Fatalf("Allocate of claim-b: %v", err)
+	}
+	if result.AvailableOnNodes == nil {
+		t.Errorf("no node in the allocation result of claim-b")
+	}
+}
+
+// A claim of the pod that does not fit anymore keeps the others from being allocated
+func TestPodClaimsAllOrNothing(t *testing.T) {
+	claimA := testDelayedClaim("claim-a")
+	claimB := testDelayedClaim("claim-b")
+	pod := testPod("pod", claimA, claimB)
+	c := newTestCluster(t, testMAS("node1", testDevice("dev0"), testDevice("dev1")), testClass(), claimA, claimB, pod)
+	d := c.newDriver(t)
+
+	if err := d.UnsuitableNodes(context.TODO(), pod, testClaimAllocations(claimA, claimB), []string{"node1"}); err != nil {
+		t.Fatalf("UnsuitableNodes: %v", err)
+	}
+	picked := d.PendingClaimRequests.Get("claim-b-uid", "node1").Mydevices[0].UID
+
+	// another claim takes the device picked for claim-b in the meantime
+	c.updateMAS(t, d, "node1", func(mas *v1alpha.MydeviceAllocationState) {
+		if mas.Spec.ResourceClaimAllocations == nil {
+			mas.Spec.ResourceClaimAllocations = make(map[string]v1alpha.AllocatedMydevices)
+		}
+		device := mas.Spec.AllocatableMydevices[picked]
+		mas.Spec.ResourceClaimAllocations["intruder-uid"] = v1alpha.AllocatedMydevices{
+			{UID: picked, Type: device.Type, CDIDevice: device.CDIDevice, MaxSharers: 1},
+		}
+	})
+
+	if _, err := d.Allocate(context.TODO(), claimA, countSpec(1), testClass(), classSpec(0), "node1"); err == nil {
+		t.Fatalf("claim-a allocated without claim-b")
+	}
+	allocations := c.getMAS(t, "node1").Spec.ResourceClaimAllocations
+	for _, claimUID := range []string{"claim-a-uid", "claim-b-uid"} {
+		if _, allocated := allocations[claimUID]; allocated {
+			t.Errorf("claim %v allocated although the pod does not fit", claimUID)
+		}
+	}
+}
+
+// If a sibling's pending request expired before the claim's own, devices are
+// picked for the whole pod again instead of allocating the claim alone
+func TestPodClaimsPickedAgainWhenSiblingRequestExpired(t *testing.T) {
+	claimA := testDelayedClaim("claim-a")
+	claimB := testDelayedClaim("claim-b")
+	pod := testPod("pod", claimA, claimB)
+	c := newTestCluster(t, testMAS("node1", testDevice("dev0"), testDevice("dev1")), testClass(), claimA, claimB, pod)
+	d := c.newDriver(t)
+
+	if err := d.UnsuitableNodes(context.TODO(), pod, testClaimAllocations(claimA, claimB), []string{"node1"}); err != nil {
+		t.Fatalf("UnsuitableNodes: %v", err)
+	}
+	d.PendingClaimRequests.Remove("claim-b-uid")
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		// the DRA controller retries failed allocations
+		if _, err = d.Allocate(context.TODO(), claimA, countSpec(1), testClass(), classSpec(0), "node1"); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("Allocate: %v", err)
+	}
+
+	allocations := c.getMAS(t, "node1").Spec.ResourceClaimAllocations
+	a, b := allocations["claim-a-uid"], allocations["claim-b-uid"]
+	if len(a) != 1 || len(b) != 1 {
+		t.Fatalf("expected both claims allocated together, got %v", allocations)
+	}
+	if a[0].UID == b[0].UID {
+		t.Errorf("claims of the pod got the same exclusive device %v", a[0].UID)
+	}
+}
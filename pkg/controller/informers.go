@@ -0,0 +1,55 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/informers"
+
+	myinformers "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/informers/externalversions"
+)
+
+// Informers are the shared informer factories the driver reads from
+type Informers struct {
+	Core    informers.SharedInformerFactory
+	Example myinformers.SharedInformerFactory // driver namespace only
+	// objects created by users in their own namespaces
+	ExampleAllNamespaces myinformers.SharedInformerFactory
+}
+
+func (i *Informers) Start(stopCh <-chan struct{}) {
+	i.Core.Start(stopCh)
+	i.Example.Start(stopCh)
+	i.ExampleAllNamespaces.Start(stopCh)
+}
+
+func (i *Informers) WaitForCacheSync(stopCh <-chan struct{}) error {
+	for informerType, synced := range i.Core.WaitForCacheSync(stopCh) {
+		if !synced {
+			return fmt.Errorf("failed to sync informer cache for %v", informerType)
+		}
+	}
+	for _, factory := range []myinformers.SharedInformerFactory{i.Example, i.ExampleAllNamespaces} {
+		for informerType, synced := range factory.WaitForCacheSync(stopCh) {
+			if !synced {
+				return fmt.Errorf("failed to sync informer cache for %v", informerType)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	resourcev1alpha1 "k8s.io/api/resource/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+
+	mylisters "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/listers/example/v1alpha"
+)
+
+const (
+	metricsNamespace = "dra_example"
+	metricsSubsystem = "controller"
+
+	// values of the mode label
+	allocationModeImmediate = "immediate"
+	allocationModeDelayed   = "delayed"
+
+	// values of the reason label
+	failureReasonNoNode              = "no_node"
+	failureReasonInsufficientDevices = "insufficient_devices"
+	failureReasonMASNotReady         = "mas_not_ready"
+	failureReasonUpdateConflict      = "update_conflict"
+	failureReasonQuotaExceeded       = "quota_exceeded"
+	failureReasonPreempting          = "preempting"
+	failureReasonError               = "error"
+)
+
+var (
+	allocationDuration = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "allocation_duration_seconds",
+			Help:           "Latency of Allocate calls, by allocation mode.",
+			Buckets:        metrics.ExponentialBuckets(0.001, 2, 15),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"mode"},
+	)
+
+	deallocationDuration = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "deallocation_duration_seconds",
+			Help:           "Latency of Deallocate calls, by allocation mode.",
+			Buckets:        metrics.ExponentialBuckets(0.001, 2, 15),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"mode"},
+	)
+
+	allocationFailures = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "allocation_failures_total",
+			Help:           "Number of failed Allocate calls, by allocation mode and reason.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"mode", "reason"},
+	)
+
+	unsuitableNodes = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "unsuitable_nodes_total",
+			Help:           "Number of potential nodes marked unsuitable for a pod, by reason.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"reason"},
+	)
+
+	allocatableDevicesDesc = metrics.NewDesc(
+		metrics.BuildFQName(metricsNamespace, metricsSubsystem, "allocatable_devices"),
+		"Number of devices published by the kubelet plugin in MydeviceAllocationState, by node and type.",
+		[]string{"node", "type"}, nil,
+		metrics.ALPHA, "",
+	)
+
+	allocatedDevicesDesc = metrics.NewDesc(
+		metrics.BuildFQName(metricsNamespace, metricsSubsystem, "allocated_devices"),
+		"Number of devices allocated to at least one ResourceClaim in MydeviceAllocationState, by node and type.",
+		[]string{"node", "type"}, nil,
+		metrics.ALPHA, "",
+	)
+
+	registerMetricsOnce sync.Once
+)
+
+// Register driver metrics in the legacy registry served by the HTTP endpoint
+func RegisterMetrics(d *Driver) {
+	registerMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(allocationDuration)
+		legacyregistry.MustRegister(deallocationDuration)
+		legacyregistry.MustRegister(allocationFailures)
+		legacyregistry.MustRegister(unsuitableNodes)
+		legacyregistry.CustomMustRegister(&masCollector{masLister: d.masLister})
+	})
+}
+
+// Meant to be deferred, start is evaluated when the defer statement runs
+func observeDuration(histogram *metrics.HistogramVec, mode string, start time.Time) {
+	histogram.WithLabelValues(mode).Observe(time.Since(start).Seconds())
+}
+
+func allocationModeLabel(mode resourcev1alpha1.AllocationMode) string {
+	if mode == resourcev1alpha1.AllocationModeImmediate {
+		return allocationModeImmediate
+	}
+	return allocationModeDelayed
+}
+
+// Reason label for a failed MAS update
+func updateFailureReason(err error) string {
+	switch {
+	case err == errInsufficientResources:
+		return failureReasonInsufficientDevices
+	case err == errMASNotReady:
+		return failureReasonMASNotReady
+	case errors.IsConflict(err):
+		return failureReasonUpdateConflict
+	default:
+		return failureReasonError
+	}
+}
+
+// masCollector reports device counts from the MAS informer cache on every scrape
+type masCollector struct {
+	metrics.BaseStableCollector
+
+	masLister mylisters.MydeviceAllocationStateNamespaceLister
+}
+
+var _ metrics.StableCollector = (*masCollector)(nil)
+
+func (c *masCollector) DescribeWithStability(ch chan<- *metrics.Desc) {
+	ch <- allocatableDevicesDesc
+	ch <- allocatedDevicesDesc
+}
+
+func (c *masCollector) CollectWithStability(ch chan<- metrics.Metric) {
+	mass, err := c.masLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Error listing MydeviceAllocationStates for metrics: %v", err)
+		return
+	}
+
+	for _, mas := range mass {
+		allocatable := map[string]int{}
+		for _, device := range mas.Spec.AllocatableMydevices {
+			allocatable[string(device.Type)]++
+		}
+
+		// shared devices are allocated to several claims, count them once
+		allocatedUIDs := map[string]string{}
+		for _, devices := range mas.Spec.ResourceClaimAllocations {
+			for _, device := range devices {
+				allocatedUIDs[device.UID] = string(device.Type)
+			}
+		}
+		allocated := map[string]int{}
+		for _, deviceType := range allocatedUIDs {
+			allocated[deviceType]++
+		}
+
+		for deviceType, count := range allocatable {
+			ch <- metrics.NewLazyConstMetric(allocatableDevicesDesc, metrics.GaugeValue, float64(count), mas.Name, deviceType)
+			ch <- metrics.NewLazyConstMetric(allocatedDevicesDesc, metrics.GaugeValue, float64(allocated[deviceType]), mas.Name, deviceType)
+		}
+		// devices that are still allocated but no longer published
+		for deviceType, count := range allocated {
+			if _, exists := allocatable[deviceType]; !exists {
+				ch <- metrics.NewLazyConstMetric(allocatedDevicesDesc, metrics.GaugeValue, float64(count), mas.Name, deviceType)
+			}
+		}
+	}
+}
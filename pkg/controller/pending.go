@@ -0,0 +1,159 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/dynamic-resource-allocation/controller"
+	"k8s.io/klog/v2"
+
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+// Pending requests are written to the MAS next to the allocations, so that a
+// controller taking over after a restart or leader change continues with the
+// devices picked for the scheduler instead of picking them again. Requests
+// without allocation are dropped from the MAS once they are gone from memory.
+
+// Write the pending requests of the claims on the node to its MAS, unless they
+// are there already
+func (d *Driver) writePendingRequests(node string, cas []*controller.ClaimAllocation) {
+	d.lock.Get(node).Lock()
+	defer d.lock.Get(node).Unlock()
+
+	mas, err := d.getMAS(node)
+	if err != nil {
+		klog.Errorf("Error retrieving MAS CRD for node %v: %v", node, err)
+		return
+	}
+
+	requests := make(map[string]mycrd.RequestedMydevices)
+	for _, ca := range cas {
+		claimUID := string(ca.Claim.UID)
+		if _, allocated := mas.Spec.ResourceClaimAllocations[claimUID]; allocated || !d.PendingClaimRequests.Exists(claimUID, node) {
+			continue
+		}
+		request := d.PendingClaimRequests.Get(claimUID, node)
+		request.Siblings = d.PendingClaimRequests.GetSiblings(claimUID, node)
+		if written, exists := mas.Spec.ResourceClaimRequests[claimUID]; !exists || !equality.Semantic.DeepEqual(written, request) {
+			requests[claimUID] = request
+		}
+	}
+	if len(requests) == 0 {
+		return
+	}
+
+	err = d.updateMASWithRetry(mas, func(mas *mycrd.MydeviceAllocationState) error {
+		if mas.Spec.ResourceClaimRequests == nil {
+			mas.Spec.ResourceClaimRequests = make(map[string]mycrd.RequestedMydevices)
+		}
+		for claimUID, request := range requests {
+			if _, allocated := mas.Spec.ResourceClaimAllocations[claimUID]; !allocated {
+				mas.Spec.ResourceClaimRequests[claimUID] = request
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// the requests stay in memory, only a failover loses them
+		klog.Errorf("Error writing pending requests to MAS of node %v: %v", node, err)
+	}
+}
+
+// RestorePendingClaimRequests loads the pending requests written to the MAS
+// objects by a previous controller instance. Call it once the informer caches
+// are synced, before claims are allocated.
+func (d *Driver) RestorePendingClaimRequests() error {
+	mass, err := d.masLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	restored := 0
+	for _, mas := range mass {
+		for claimUID, request := range mas.Spec.ResourceClaimRequests {
+			if _, allocated := mas.Spec.ResourceClaimAllocations[claimUID]; allocated {
+				continue
+			}
+			if d.pendingClaimStale(claimUID) || d.PendingClaimRequests.Exists(claimUID, mas.Name) {
+				continue
+			}
+
+			siblings := request.Siblings
+			request = *request.DeepCopy()
+			request.Siblings = nil
+			d.PendingClaimRequests.Set(claimUID, mas.Name, request)
+			d.PendingClaimRequests.SetSiblings(claimUID, mas.Name, siblings)
+			restored++
+		}
+	}
+	klog.V(3).Infof("Restored %v pending claim requests", restored)
+	return nil
+}
+
+// Drop pending requests from the MAS objects that are gone from memory, because
+// they expired or their claim was allocated elsewhere or deleted
+func (d *Driver) prunePendingRequests() {
+	masnames, err := d.listMASNames()
+	if err != nil {
+		klog.Errorf("Error listing MAS objects: %v", err)
+		return
+	}
+
+	for _, node := range masnames {
+		err := d.prunePendingRequestsOnNode(node)
+		if err != nil {
+			klog.Errorf("Error pruning pending requests on node %v: %v", node, err)
+		}
+	}
+}
+
+func (d *Driver) prunePendingRequestsOnNode(node string) error {
+	d.lock.Get(node).Lock()
+	defer d.lock.Get(node).Unlock()
+
+	mas, err := d.getMAS(node)
+	if err != nil {
+		return err
+	}
+
+	stale := func(mas *mycrd.MydeviceAllocationState, claimUID string) bool {
+		_, allocated := mas.Spec.ResourceClaimAllocations[claimUID]
+		return !allocated && !d.PendingClaimRequests.Exists(claimUID, node)
+	}
+
+	pruned := 0
+	for claimUID := range mas.Spec.ResourceClaimRequests {
+		if stale(mas, claimUID) {
+			pruned++
+		}
+	}
+	if pruned == 0 {
+		return nil
+	}
+
+	klog.V(3).Infof("Pruning %v pending requests from MAS of node %v", pruned, node)
+	return d.updateMASWithRetry(mas, func(mas *mycrd.MydeviceAllocationState) error {
+		for claimUID := range mas.Spec.ResourceClaimRequests {
+			if stale(mas, claimUID) {
+				delete(mas.Spec.ResourceClaimRequests, claimUID)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+func TestFreshDriverContinuesPendingAllocation(t *testing.T) {
+	claimA := testDelayedClaim("claim-a")
+	claimB := testDelayedClaim("claim-b")
+	// the pod is not in the cluster, so a driver that lost the pending requests
+	// cannot find the claims allocated together with claim-a
+	pod := testPod("pod", claimA, claimB)
+	c := newTestCluster(t, testMAS("node1", testDevice("dev0"), testDevice("dev1"), testDevice("dev2")), testClass(), claimA, claimB)
+
+	d := c.newDriver(t)
+	cas := testClaimAllocations(claimA, claimB)
+	if err := d.UnsuitableNodes(context.TODO(), pod, cas, []string{"node1"}); err != nil {
+		t.Fatalf("UnsuitableNodes: %v", err)
+	}
+	for _, ca := range cas {
+		if len(ca.UnsuitableNodes) > 0 {
+			t.Fatalf("claim %v: unexpected unsuitable nodes %v", ca.Claim.Name, ca.UnsuitableNodes)
+		}
+	}
+	picked := map[string]mycrd.RequestedMydevices{}
+	for _, claim := range []string{"claim-a-uid", "claim-b-uid"} {
+		picked[claim] = d.PendingClaimRequests.Get(claim, "node1")
+		if _, exists := c.getMAS(t, "node1").Spec.ResourceClaimRequests[claim]; !exists {
+			t.Fatalf("pending request of %v not written to MAS", claim)
+		}
+	}
+
+	// another controller instance takes over before the claims are allocated
+	fresh := c.newDriver(t)
+	if _, err := fresh.Allocate(context.TODO(), claimA, countSpec(1), testClass(), classSpec(0), "node1"); err != nil {
+		t.Fatalf("Allocate: %v", err)
+	}
+
+	mas := c.getMAS(t, "node1")
+	for claim, request := range picked {
+		allocation, exists := mas.Spec.ResourceClaimAllocations[claim]
+		if !exists {
+			t.Fatalf("claim %v not allocated together with claim-a", claim)
+		}
+		if len(allocation) != 1 || allocation[0].UID != request.Mydevices[0].UID {
+			t.Errorf("claim %v: expected device %v picked by the previous driver, got %+v", claim, request.Mydevices[0].UID, allocation)
+		}
+	}
+}
+
+func TestPrunePendingRequests(t *testing.T) {
+	claim := testDelayedClaim("claim-a")
+	request := mycrd.RequestedMydevices{
+		Spec:           *countSpec(1),
+		Mydevices:      []mycrd.RequestedMydevice{{UID: "dev0", MaxSharers: 1}},
+		AllocatedCount: 1,
+	}
+	mas := testMAS("node1", testDevice("dev0"), testDevice("dev1"))
+	mas.Spec.ResourceClaimRequests = map[string]mycrd.RequestedMydevices{
+		"claim-a-uid": request,
+		// claim deleted while the controller was down
+		"deleted-uid": request,
+	}
+	c := newTestCluster(t, mas, testClass(), claim)
+
+	d := c.newDriver(t)
+	if !equality.Semantic.DeepEqual(d.PendingClaimRequests.Get("claim-a-uid", "node1"), request) {
+		t.Errorf("pending request of claim-a not restored")
+	}
+	if d.PendingClaimRequests.Exists("deleted-uid", "node1") {
+		t.Errorf("pending request of deleted claim restored")
+	}
+
+	d.prunePendingRequests()
+	requests := c.getMAS(t, "node1").Spec.ResourceClaimRequests
+	if _, exists := requests["claim-a-uid"]; !exists {
+		t.Errorf("pending request of claim-a pruned")
+	}
+	if _, exists := requests["deleted-uid"]; exists {
+		t.Errorf("pending request of deleted claim not pruned")
+	}
+}
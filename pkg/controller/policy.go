@@ -0,0 +1,103 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sort"
+
+	"k8s.io/klog/v2"
+
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+// nodeScorer rates a node for immediate allocation, nodes with higher score are tried first
+type nodeScorer func(mas *mycrd.MydeviceAllocationState) float64
+
+var nodeScorers = map[mycrd.AllocationPolicy]nodeScorer{
+	mycrd.AllocationPolicyPack:   packScore,
+	mycrd.AllocationPolicySpread: spreadScore,
+}
+
+// Prefer nodes with most devices in use, keeps other nodes free for large claims
+func packScore(mas *mycrd.MydeviceAllocationState) float64 {
+	return allocatedRatio(mas)
+}
+
+// Prefer nodes with least devices in use
+func spreadScore(mas *mycrd.MydeviceAllocationState) float64 {
+	return -allocatedRatio(mas)
+}
+
+func allocatedRatio(mas *mycrd.MydeviceAllocationState) float64 {
+	if len(mas.Spec.AllocatableMydevices) == 0 {
+		return 0
+	}
+
+	allocated := 0
+	for uid := range mas.Spec.AllocatableMydevices {
+		if mas.DeviceIsAllocated(uid) {
+			allocated++
+		}
+	}
+	return float64(allocated) / float64(len(mas.Spec.AllocatableMydevices))
+}
+
+// Order nodes according to resource class allocation policy.
+// Without policy the order is kept, nodes without MAS are moved to the end.
+func (d *Driver) sortNodesByPolicy(masnames []string, classParameters interface{}) []string {
+	classParamsSpec, ok := classParameters.(*mycrd.MydeviceClassParametersSpec)
+	if !ok || classParamsSpec == nil || classParamsSpec.AllocationPolicy == "" {
+		return masnames
+	}
+
+	scorer, exists := nodeScorers[classParamsSpec.AllocationPolicy]
+	if !exists {
+		klog.Warningf("Unknown allocation policy '%v', keeping node order", classParamsSpec.AllocationPolicy)
+		return masnames
+	}
+
+	type scoredNode struct {
+		name   string
+		score  float64
+		scored bool
+	}
+
+	nodes := make([]scoredNode, 0, len(masnames))
+	for _, nodename := range masnames {
+		node := scoredNode{name: nodename}
+		if mas, err := d.getMAS(nodename); err == nil {
+			node.score = scorer(mas)
+			node.scored = true
+		}
+		nodes = append(nodes, node)
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].scored != nodes[j].scored {
+			return nodes[i].scored
+		}
+		return nodes[i].score > nodes[j].score
+	})
+
+	sorted := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		sorted = append(sorted, node.name)
+	}
+	klog.V(5).Infof("Nodes ordered by %v policy: %v", classParamsSpec.AllocationPolicy, sorted)
+
+	return sorted
+}
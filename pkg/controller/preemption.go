@@ -0,0 +1,321 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	resourcev1alpha1 "k8s.io/api/resource/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/dynamic-resource-allocation/controller"
+	"k8s.io/klog/v2"
+
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+const (
+	eventReasonPreempted  = "Preempted"
+	eventReasonPreempting = "Preempting"
+)
+
+// preemptionPlan lists the claims to deallocate from a node and the devices
+// the preempting claims get once they are gone
+type preemptionPlan struct {
+	victims   []string
+	allocated map[string]mycrd.RequestedMydevices
+	// victims that are not being deallocated yet
+	newVictims int
+}
+
+// Fill in the claim priority from the consuming pod, whose priority the API server
+// resolves from its PriorityClass. Claim parameters are written by users and may
+// only lower it, a claim without pod gets at most the default priority 0. Without
+// a pod at hand, the pod owning the claim is used, if any.
+func (d *Driver) resolveClaimPriority(claim *resourcev1alpha1.ResourceClaim, claimParamsSpec *mycrd.MydeviceClaimParametersSpec, pod *corev1.Pod) error {
+	if pod == nil {
+		var err error
+		pod, err = d.ownerPod(claim)
+		if err != nil {
+			return err
+		}
+	}
+
+	var bound int32
+	if pod != nil && pod.Spec.Priority != nil {
+		bound = *pod.Spec.Priority
+	} else if claimParamsSpec.Priority == nil {
+		return nil
+	}
+
+	if claimParamsSpec.Priority == nil || *claimParamsSpec.Priority > bound {
+		claimParamsSpec.Priority = &bound
+	}
+	return nil
+}
+
+// Pod the claim was generated for from a template, read from the informer cache.
+// Returns nil if there is none or it is gone.
+func (d *Driver) ownerPod(claim *resourcev1alpha1.ResourceClaim) (*corev1.Pod, error) {
+	owner := metav1.GetControllerOf(claim)
+	if owner == nil || owner.APIVersion != "v1" || owner.Kind != "Pod" {
+		return nil, nil
+	}
+
+	pod, err := d.podLister.Pods(claim.Namespace).Get(owner.Name)
+	if errors.IsNotFound(err) || (err == nil && pod.UID != owner.UID) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting owner pod %v/%v of claim %v: %v", claim.Namespace, owner.Name, claim.Name, err)
+	}
+	return pod, nil
+}
+
+func claimPriority(claimParamsSpec *mycrd.MydeviceClaimParametersSpec) int32 {
+	if claimParamsSpec == nil || claimParamsSpec.Priority == nil {
+		return 0
+	}
+	return *claimParamsSpec.Priority
+}
+
+// Lowest priority among the claims, a victim must have lower priority than all of them
+func claimsPriority(mcas []*controller.ClaimAllocation) int32 {
+	priority := int32(math.MaxInt32)
+	for _, ca := range mcas {
+		claimParamsSpec := ca.ClaimParameters.(*mycrd.MydeviceClaimParametersSpec)
+		if p := claimPriority(claimParamsSpec); p < priority {
+			priority = p
+		}
+	}
+	return priority
+}
+
+// Find lower priority claims on the node whose devices, once freed, let all
+// claims fit. Claims already being deallocated are taken as free. Victims are
+// picked lowest priority first, then those not needed after all are spared,
+// highest priority first. Returns nil if preempting does not help.
+func (d *Driver) planPreemption(mas *mycrd.MydeviceAllocationState, mcas []*controller.ClaimAllocation) *preemptionPlan {
+	priority := claimsPriority(mcas)
+
+	// simulate on a copy, the clientset is never used
+	simulated := mycrd.NewMydeviceAllocationStateFromObject(mas.MydeviceAllocationState, nil)
+	if simulated.Spec.ResourceClaimRequests == nil {
+		simulated.Spec.ResourceClaimRequests = make(map[string]mycrd.RequestedMydevices)
+	}
+
+	type candidate struct {
+		uid        string
+		priority   int32
+		allocation mycrd.AllocatedMydevices
+		request    mycrd.RequestedMydevices
+	}
+
+	victims := []string{}
+	candidates := []candidate{}
+	for claimUID, allocation := range simulated.Spec.ResourceClaimAllocations {
+		if claim, exists := d.getClaimByUID(claimUID); exists && claim.Status.DeallocationRequested {
+			victims = append(victims, claimUID)
+			delete(simulated.Spec.ResourceClaimAllocations, claimUID)
+			continue
+		}
+
+		request := simulated.Spec.ResourceClaimRequests[claimUID]
+		if claimPriority(&request.Spec) < priority {
+			candidates = append(candidates, candidate{claimUID, claimPriority(&request.Spec), allocation, request})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].priority != candidates[j].priority {
+			return candidates[i].priority < candidates[j].priority
+		}
+		return candidates[i].uid < candidates[j].uid
+	})
+
+	fits := func() (map[string]mycrd.RequestedMydevices, bool) {
+		allocated := d.selectPotentialDevices(simulated, mcas)
+		for _, ca := range mcas {
+			claimParamsSpec := ca.ClaimParameters.(*mycrd.MydeviceClaimParametersSpec)
+			if !mycrd.CountSatisfied(claimParamsSpec, len(allocated[string(ca.Claim.UID)].Mydevices)) {
+				return nil, false
+			}
+		}
+		return allocated, true
+	}
+
+	preempted := []candidate{}
+	_, ok := fits()
+	for _, c := range candidates {
+		if ok {
+			break
+		}
+		delete(simulated.Spec.ResourceClaimAllocations, c.uid)
+		delete(simulated.Spec.ResourceClaimRequests, c.uid)
+		preempted = append(preempted, c)
+		_, ok = fits()
+	}
+	if !ok {
+		return nil
+	}
+
+	needed := []candidate{}
+	for i := len(preempted) - 1; i >= 0; i-- {
+		c := preempted[i]
+		simulated.Spec.ResourceClaimAllocations[c.uid] = c.allocation
+		simulated.Spec.ResourceClaimRequests[c.uid] = c.request
+		if _, ok := fits(); !ok {
+			delete(simulated.Spec.ResourceClaimAllocations, c.uid)
+			delete(simulated.Spec.ResourceClaimRequests, c.uid)
+			needed = append(needed, c)
+		}
+	}
+	for _, c := range needed {
+		victims = append(victims, c.uid)
+	}
+
+	allocated, _ := fits()
+	return &preemptionPlan{
+		victims:    victims,
+		allocated:  allocated,
+		newVictims: len(needed),
+	}
+}
+
+// Request deallocation of the victims and evict the pods using them. The DRA
+// controller calls Deallocate for a victim once none of its pods is left.
+func (d *Driver) preempt(ctx context.Context, preemptor *resourcev1alpha1.ResourceClaim, nodename string, victims []string) error {
+	for _, claimUID := range victims {
+		claim, exists := d.getClaimByUID(claimUID)
+		if !exists {
+			continue
+		}
+
+		if !claim.Status.DeallocationRequested {
+			klog.Infof("Preempting claim %v/%v on node %v for claim %v/%v", claim.Namespace, claim.Name, nodename, preemptor.Namespace, preemptor.Name)
+			claim = claim.DeepCopy()
+			claim.Status.DeallocationRequested = true
+			_, err := d.coreclient.ResourceV1alpha1().ResourceClaims(claim.Namespace).UpdateStatus(ctx, claim, metav1.UpdateOptions{})
+			if err != nil {
+				return fmt.Errorf("error requesting deallocation of claim %v/%v: %v", claim.Namespace, claim.Name, err)
+			}
+			d.recorder.Eventf(claim, corev1.EventTypeWarning, eventReasonPreempted,
+				"Preempted on node %v by higher priority claim %v/%v", nodename, preemptor.Namespace, preemptor.Name)
+		}
+
+		for _, consumer := range claim.Status.ReservedFor {
+			if consumer.APIGroup != "" || consumer.Resource != "pods" {
+				continue
+			}
+			eviction := &policyv1.Eviction{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      consumer.Name,
+					Namespace: claim.Namespace,
+				},
+			}
+			err := d.coreclient.PolicyV1().Evictions(claim.Namespace).Evict(ctx, eviction)
+			if err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("error evicting pod %v/%v: %v", claim.Namespace, consumer.Name, err)
+			}
+		}
+	}
+
+	d.recorder.Eventf(preemptor, corev1.EventTypeNormal, eventReasonPreempting,
+		"Waiting for %d lower priority claims to be deallocated from node %v", len(victims), nodename)
+	return nil
+}
+
+// Second chance for a node rejected for lack of devices: it is suitable if
+// preempting lower priority claims frees enough devices.
+func (d Driver) preemptibleNode(allcas []*controller.ClaimAllocation, potentialNode string) bool {
+	d.lock.Get(potentialNode).Lock()
+	defer d.lock.Get(potentialNode).Unlock()
+
+	mas, err := d.getMAS(potentialNode)
+	if err != nil || mas.Status != mycrd.MydeviceAllocationStateStatusReady {
+		return false
+	}
+	if mas.Spec.ResourceClaimRequests == nil {
+		mas.Spec.ResourceClaimRequests = make(map[string]mycrd.RequestedMydevices)
+	}
+
+	mcas := []*controller.ClaimAllocation{}
+	for _, ca := range allcas {
+		if _, ok := ca.ClaimParameters.(*mycrd.MydeviceClaimParametersSpec); ok {
+			mcas = append(mcas, ca)
+		}
+	}
+
+	if len(mcas) == 0 {
+		return false
+	}
+
+	plan := d.pickPreemptionDevices(mas, mcas)
+	if plan == nil {
+		return false
+	}
+
+	klog.V(3).Infof("Node %v is suitable after preempting claims %v", potentialNode, plan.victims)
+	return true
+}
+
+// Pick devices on the node for all claims of a pod assuming lower priority
+// claims get preempted, and remember them as pending requests of one group
+// together with the victims. The node lock must be held. Returns nil if
+// preempting does not help.
+func (d *Driver) pickPreemptionDevices(mas *mycrd.MydeviceAllocationState, mcas []*controller.ClaimAllocation) *preemptionPlan {
+	d.PendingClaimRequests.CleanupNode(mas)
+	plan := d.planPreemption(mas, mcas)
+	if plan == nil {
+		return nil
+	}
+
+	for _, ca := range mcas {
+		claimUID := string(ca.Claim.UID)
+		d.PendingClaimRequests.Set(claimUID, mas.Name, plan.allocated[claimUID])
+		d.PendingClaimRequests.SetVictims(claimUID, mas.Name, plan.victims)
+	}
+	d.setPendingClaimGroup(mas.Name, mcas)
+	return plan
+}
+
+// Node where preempting the fewest claims lets the claims fit, nodes earlier
+// in the list win ties
+func (d *Driver) bestPreemptionNode(masnames []string, mcas []*controller.ClaimAllocation) (string, *preemptionPlan) {
+	var bestNode string
+	var bestPlan *preemptionPlan
+	for _, nodename := range masnames {
+		d.lock.Get(nodename).Lock()
+		mas, err := d.getMAS(nodename)
+		if err != nil || mas.Status != mycrd.MydeviceAllocationStateStatusReady {
+			d.lock.Get(nodename).Unlock()
+			continue
+		}
+		plan := d.planPreemption(mas, mcas)
+		d.lock.Get(nodename).Unlock()
+
+		if plan != nil && (bestPlan == nil || plan.newVictims < bestPlan.newVictims) {
+			bestNode = nodename
+			bestPlan = plan
+		}
+	}
+	return bestNode, bestPlan
+}
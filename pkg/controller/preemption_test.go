@@ -0,0 +1,283 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	resourcev1alpha1 "k8s.io/api/resource/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/dynamic-resource-allocation/controller"
+
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func priorityString(priority *int32) string {
+	if priority == nil {
+		return "nil"
+	}
+	return fmt.Sprint(*priority)
+}
+
+// Record the claim as allocated on the MAS with the devices and priority
+func allocateWithPriority(mas *v1alpha.MydeviceAllocationState, claim *resourcev1alpha1.ResourceClaim, priority int32, deviceUIDs ...string) {
+	allocateOnMAS(mas, claim, deviceUIDs...)
+	request := mas.Spec.ResourceClaimRequests[string(claim.UID)]
+	request.Spec.Priority = int32Ptr(priority)
+	mas.Spec.ResourceClaimRequests[string(claim.UID)] = request
+}
+
+func prioritySpec(count int, priority int32) *mycrd.MydeviceClaimParametersSpec {
+	spec := countSpec(count)
+	spec.Priority = int32Ptr(priority)
+	return spec
+}
+
+func TestResolveClaimPriority(t *testing.T) {
+	owner := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: testClaimNamespace, UID: "owner-uid"},
+		Spec:       corev1.PodSpec{Priority: int32Ptr(100)},
+	}
+	isController := true
+	ownedBy := func(name string, uid string) *resourcev1alpha1.ResourceClaim {
+		claim := testClaim("owned")
+		claim.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Name:       name,
+			UID:        types.UID(uid),
+			Controller: &isController,
+		}}
+		return claim
+	}
+	podWithPriority := func(priority *int32) *corev1.Pod {
+		return &corev1.Pod{Spec: corev1.PodSpec{Priority: priority}}
+	}
+
+	testCases := []struct {
+		name     string
+		claim    *resourcev1alpha1.ResourceClaim
+		pod      *corev1.Pod
+		priority *int32
+		expected *int32
+	}{
+		{"no pod, no priority", testClaim("claim"), nil, nil, nil},
+		{"no pod, capped at default", testClaim("claim"), nil, int32Ptr(5), int32Ptr(0)},
+		{"no pod, lower priority kept", testClaim("claim"), nil, int32Ptr(-3), int32Ptr(-3)},
+		{"pod priority", testClaim("claim"), podWithPriority(int32Ptr(100)), nil, int32Ptr(100)},
+		{"pod without priority", testClaim("claim"), podWithPriority(nil), int32Ptr(5), int32Ptr(0)},
+		{"lowered by claim", testClaim("claim"), podWithPriority(int32Ptr(100)), int32Ptr(50), int32Ptr(50)},
+		{"capped by pod", testClaim("claim"), podWithPriority(int32Ptr(100)), int32Ptr(200), int32Ptr(100)},
+		{"owner pod", ownedBy("owner", "owner-uid"), nil, int32Ptr(200), int32Ptr(100)},
+		{"owner pod gone", ownedBy("gone", "gone-uid"), nil, int32Ptr(200), int32Ptr(0)},
+		{"owner pod replaced", ownedBy("owner", "old-uid"), nil, int32Ptr(200), int32Ptr(0)},
+	}
+
+	d := newTestCluster(t, owner).newDriver(t)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := countSpec(1)
+			spec.Priority = tc.priority
+			if err := d.resolveClaimPriority(tc.claim, spec, tc.pod); err != nil {
+				t.Fatalf("resolveClaimPriority: %v", err)
+			}
+			if !reflect.DeepEqual(spec.Priority, tc.expected) {
+				t.Errorf("expected priority %v, got %v", priorityString(tc.expected), priorityString(spec.Priority))
+			}
+		})
+	}
+}
+
+func TestPlanPreemption(t *testing.T) {
+	type holder struct {
+		name         string
+		priority     int32
+		devices      []string
+		deallocating bool
+	}
+
+	testCases := []struct {
+		name       string
+		holders    []holder
+		preemptor  *mycrd.MydeviceClaimParametersSpec
+		victims    []string
+		newVictims int
+	}{
+		{
+			name:       "lowest priority first",
+			holders:    []holder{{"low", 1, []string{"dev0"}, false}, {"mid", 2, []string{"dev1"}, false}, {"high", 5, []string{"dev2"}, false}},
+			preemptor:  prioritySpec(1, 3),
+			victims:    []string{"low-uid"},
+			newVictims: 1,
+		},
+		{
+			name:       "several victims",
+			holders:    []holder{{"low", 1, []string{"dev0"}, false}, {"mid", 2, []string{"dev1"}, false}, {"high", 5, []string{"dev2"}, false}},
+			preemptor:  prioritySpec(2, 3),
+			victims:    []string{"low-uid", "mid-uid"},
+			newVictims: 2,
+		},
+		{
+			name:      "higher priority claims are kept",
+			holders:   []holder{{"low", 1, []string{"dev0"}, false}, {"mid", 2, []string{"dev1"}, false}, {"high", 5, []string{"dev2"}, false}},
+			preemptor: prioritySpec(3, 3),
+		},
+		{
+			name:      "equal priority claims are kept",
+			holders:   []holder{{"low", 1, []string{"dev0"}, false}, {"mid", 2, []string{"dev1"}, false}, {"high", 5, []string{"dev2"}, false}},
+			preemptor: prioritySpec(2, 2),
+		},
+		{
+			name:       "victims not needed after all are spared",
+			holders:    []holder{{"low", 1, []string{"dev0"}, false}, {"mid", 2, []string{"dev1", "dev2"}, false}},
+			preemptor:  prioritySpec(2, 3),
+			victims:    []string{"mid-uid"},
+			newVictims: 1,
+		},
+		{
+			name:       "claims being deallocated count as free",
+			holders:    []holder{{"low", 1, []string{"dev0"}, false}, {"mid", 2, []string{"dev1"}, true}, {"high", 5, []string{"dev2"}, false}},
+			preemptor:  prioritySpec(1, 3),
+			victims:    []string{"mid-uid"},
+			newVictims: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mas := testMAS("node1", testDevice("dev0"), testDevice("dev1"), testDevice("dev2"))
+			objects := []runtime.Object{testClass()}
+			for _, h := range tc.holders {
+				claim := testClaim(h.name)
+				allocateWithPriority(mas, claim, h.priority, h.devices...)
+				claim.Status.DeallocationRequested = h.deallocating
+				objects = append(objects, claim)
+			}
+			c := newTestCluster(t, append(objects, mas)...)
+			d := c.newDriver(t)
+
+			current, err := d.getMAS("node1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			plan := d.planPreemption(current, []*controller.ClaimAllocation{claimAllocation("preemptor", tc.preemptor)})
+			if tc.victims == nil {
+				if plan != nil {
+					t.Fatalf("expected no plan, got victims %v", plan.victims)
+				}
+				return
+			}
+			if plan == nil {
+				t.Fatalf("expected victims %v, got no plan", tc.victims)
+			}
+
+			victims := append([]string{}, plan.victims...)
+			sort.Strings(victims)
+			if !reflect.DeepEqual(victims, tc.victims) || plan.newVictims != tc.newVictims {
+				t.Errorf("expected victims %v (%d new), got %v (%d new)", tc.victims, tc.newVictims, victims, plan.newVictims)
+			}
+			if devices := plan.allocated["preemptor-uid"].Mydevices; len(devices) != tc.preemptor.Count {
+				t.Errorf("expected %d devices for the preemptor, got %v", tc.preemptor.Count, devices)
+			}
+		})
+	}
+}
+
+// An immediate claim waits for the claims preempted for it, even if another
+// node gets free devices in the meantime
+func TestImmediatePreemptionStaysOnNode(t *testing.T) {
+	masA := testMAS("node-a", testDevice("dev0"))
+	masB := testMAS("node-b", testDevice("dev0"))
+	victimA := testClaim("victim-a")
+	victimB := testClaim("victim-b")
+	allocateWithPriority(masA, victimA, -10, "dev0")
+	allocateWithPriority(masB, victimB, -10, "dev0")
+	preemptor := testClaim("preemptor")
+	c := newTestCluster(t, masA, masB, testClass(), victimA, victimB, preemptor)
+	d := c.newDriver(t)
+
+	allocate := func() error {
+		_, err := d.Allocate(context.TODO(), preemptor, countSpec(1), testClass(), classSpec(0), "")
+		return err
+	}
+	// remove the allocation as Deallocate does and wait for the driver to see it
+	deallocate := func(node string, claimUID string) {
+		mas := c.getMAS(t, node)
+		delete(mas.Spec.ResourceClaimAllocations, claimUID)
+		delete(mas.Spec.ResourceClaimRequests, claimUID)
+		if _, err := c.exampleclient.DraV1alpha().MydeviceAllocationStates(testNamespace).Update(context.TODO(), mas, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		err := wait.PollImmediate(10*time.Millisecond, 10*time.Second, func() (bool, error) {
+			current, err := d.getMAS(node)
+			if err != nil {
+				return false, err
+			}
+			_, allocated := current.Spec.ResourceClaimAllocations[claimUID]
+			return !allocated, nil
+		})
+		if err != nil {
+			t.Fatalf("deallocation of %v on %v not seen: %v", claimUID, node, err)
+		}
+	}
+
+	if err := allocate(); err == nil || !strings.Contains(err.Error(), "waiting for 1 preempted claims") {
+		t.Fatalf("expected preemption, got %v", err)
+	}
+	node, pinned := d.PendingClaimRequests.PreemptionNode("preemptor-uid")
+	if !pinned {
+		t.Fatal("claim not pinned to the node of its victims")
+	}
+	other, victim, otherVictim := "node-b", "victim-a-uid", "victim-b-uid"
+	if node == "node-b" {
+		other, victim, otherVictim = "node-a", "victim-b-uid", "victim-a-uid"
+	}
+
+	deallocate(other, otherVictim)
+	if err := allocate(); err == nil {
+		t.Fatal("claim allocated before its victims are gone")
+	}
+	if _, allocated := c.getMAS(t, other).Spec.ResourceClaimAllocations["preemptor-uid"]; allocated {
+		t.Fatalf("claim allocated on %v instead of waiting on %v", other, node)
+	}
+
+	deallocate(node, victim)
+	if err := allocate(); err != nil {
+		t.Fatalf("Allocate: %v", err)
+	}
+	if _, allocated := c.getMAS(t, node).Spec.ResourceClaimAllocations["preemptor-uid"]; !allocated {
+		t.Errorf("claim not allocated on %v", node)
+	}
+	if _, pinned := d.PendingClaimRequests.PreemptionNode("preemptor-uid"); pinned {
+		t.Errorf("claim still pinned after allocation")
+	}
+}
@@ -0,0 +1,312 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	resourcev1alpha1 "k8s.io/api/resource/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/dynamic-resource-allocation/controller"
+	"k8s.io/klog/v2"
+
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+// how often usage in MydeviceQuota status is refreshed besides after each allocation
+const QuotaStatusSyncInterval = time.Minute
+
+func (d *Driver) namespaceHasQuota(namespace string) bool {
+	quotas, err := d.quotaLister.MydeviceQuotas(namespace).List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Error listing MydeviceQuotas in namespace %v: %v", namespace, err)
+		return false
+	}
+	return len(quotas) > 0
+}
+
+// Devices per type allocated to claims in the namespace. Only the claims of the
+// namespace and the MAS objects they are allocated on are looked at, both are
+// indexed. Allocations of excluded claims are skipped.
+func (d *Driver) namespaceUsage(namespace string, excludedClaimUIDs ...string) (mycrd.MydeviceUsage, error) {
+	excluded := make(map[string]bool)
+	for _, claimUID := range excludedClaimUIDs {
+		excluded[claimUID] = true
+	}
+
+	claims, err := d.claimIndexer.ByIndex(cache.NamespaceIndex, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error listing ResourceClaims in namespace %v: %v", namespace, err)
+	}
+
+	usage := mycrd.MydeviceUsage{}
+	for _, obj := range claims {
+		claim, ok := obj.(*resourcev1alpha1.ResourceClaim)
+		if !ok || excluded[string(claim.UID)] {
+			continue
+		}
+		claimUID := string(claim.UID)
+
+		// our own MAS writes are included before the informer catches up
+		mass, err := d.masCache.ByIndex(masClaimUIDIndex, claimUID)
+		if err != nil {
+			return nil, fmt.Errorf("error looking up MAS CRD of claim %v: %v", claimUID, err)
+		}
+		for _, obj := range mass {
+			mas, ok := obj.(*v1alpha.MydeviceAllocationState)
+			if !ok {
+				continue
+			}
+			// the newer object of the mutation cache may not hold the claim anymore
+			devices, allocated := mas.Spec.ResourceClaimAllocations[claimUID]
+			if !allocated {
+				continue
+			}
+			// a claim with a count range is charged what it got, not its maximum
+			request := mas.Spec.ResourceClaimRequests[claimUID]
+			count := request.AllocatedCount
+			if count == 0 {
+				// written before AllocatedCount was recorded
+				count = len(devices)
+			}
+			usage[claimDeviceType(&request.Spec)] += count
+		}
+	}
+
+	return usage, nil
+}
+
+func claimDeviceType(claimParamsSpec *mycrd.MydeviceClaimParametersSpec) mycrd.MydeviceType {
+	if claimParamsSpec.Type == "" {
+		return mycrd.MydeviceType0
+	}
+	return claimParamsSpec.Type
+}
+
+// Claims with a count range are admitted with their minimum, how many more
+// devices they get is limited to the headroom left by the quota
+func claimUsage(claimParamsSpec *mycrd.MydeviceClaimParametersSpec) mycrd.MydeviceUsage {
+	minCount, _ := mycrd.CountRange(claimParamsSpec)
+	return mycrd.MydeviceUsage{claimDeviceType(claimParamsSpec): minCount}
+}
+
+func claimUIDs(cas []*controller.ClaimAllocation) []string {
+	uids := []string{}
+	for _, ca := range cas {
+		uids = append(uids, string(ca.Claim.UID))
+	}
+	return uids
+}
+
+// Returns the quota limits of the namespace that the claims would exceed, empty if they fit
+func (d *Driver) quotaExceeded(namespace string, cas []*controller.ClaimAllocation) ([]string, error) {
+	quotas, err := d.quotaLister.MydeviceQuotas(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error listing MydeviceQuotas: %v", err)
+	}
+	if len(quotas) == 0 {
+		return nil, nil
+	}
+
+	requested := mycrd.MydeviceUsage{}
+	for _, ca := range cas {
+		claimParamsSpec, ok := ca.ClaimParameters.(*mycrd.MydeviceClaimParametersSpec)
+		if !ok {
+			continue
+		}
+		requested.Add(claimUsage(claimParamsSpec))
+	}
+
+	// claims that are already allocated must not be counted twice
+	used, err := d.namespaceUsage(namespace, claimUIDs(cas)...)
+	if err != nil {
+		return nil, err
+	}
+
+	exceeded := []string{}
+	for _, quota := range quotas {
+		exceeded = append(exceeded, mycrd.QuotaExceeded(quota, used, requested)...)
+	}
+	return exceeded, nil
+}
+
+// Devices per type the namespace can still get before it reaches the lowest
+// limit of its quotas. Types without a limit are missing, nil means the
+// namespace has no quota. Allocations of excluded claims are not counted.
+func (d *Driver) quotaHeadroom(namespace string, excludedClaimUIDs ...string) (mycrd.MydeviceUsage, error) {
+	quotas, err := d.quotaLister.MydeviceQuotas(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error listing MydeviceQuotas: %v", err)
+	}
+	if len(quotas) == 0 {
+		return nil, nil
+	}
+
+	used, err := d.namespaceUsage(namespace, excludedClaimUIDs...)
+	if err != nil {
+		return nil, err
+	}
+
+	headroom := mycrd.MydeviceUsage{}
+	for _, quota := range quotas {
+		for deviceType, limit := range quota.Spec.Limits {
+			left := limit - used[deviceType]
+			if left < 0 {
+				left = 0
+			}
+			if current, limited := headroom[deviceType]; !limited || left < current {
+				headroom[deviceType] = left
+			}
+		}
+	}
+	return headroom, nil
+}
+
+// Lower the maximum of claims with a count range so that the claims together
+// get no more devices than the headroom. Every claim keeps its minimum, the
+// quota check has admitted that already. Changed claims get a copy of their
+// parameters, earlier claims are served first.
+func limitToHeadroom(cas []*controller.ClaimAllocation, headroom mycrd.MydeviceUsage) {
+	if headroom == nil {
+		return
+	}
+
+	left := mycrd.MydeviceUsage{}
+	left.Add(headroom)
+	for _, ca := range cas {
+		if claimParamsSpec, ok := ca.ClaimParameters.(*mycrd.MydeviceClaimParametersSpec); ok {
+			minCount, _ := mycrd.CountRange(claimParamsSpec)
+			left[claimDeviceType(claimParamsSpec)] -= minCount
+		}
+	}
+
+	for _, ca := range cas {
+		claimParamsSpec, ok := ca.ClaimParameters.(*mycrd.MydeviceClaimParametersSpec)
+		if !ok {
+			continue
+		}
+		deviceType := claimDeviceType(claimParamsSpec)
+		if _, limited := headroom[deviceType]; !limited {
+			continue
+		}
+
+		minCount, maxCount := mycrd.CountRange(claimParamsSpec)
+		extra := maxCount - minCount
+		if extra > left[deviceType] {
+			extra = left[deviceType]
+		}
+		if extra < 0 {
+			extra = 0
+		}
+		left[deviceType] -= extra
+
+		if minCount+extra < maxCount {
+			klog.V(5).Infof("Limiting claim %v/%v to %d devices by MydeviceQuota", ca.Claim.Namespace, ca.Claim.Name, minCount+extra)
+			limited := claimParamsSpec.DeepCopy()
+			limited.MinCount = minCount
+			limited.MaxCount = minCount + extra
+			ca.ClaimParameters = limited
+		}
+	}
+}
+
+// Returns false if the devices exceed the headroom of any limited type
+func withinHeadroom(usage mycrd.MydeviceUsage, headroom mycrd.MydeviceUsage) bool {
+	for deviceType, count := range usage {
+		if left, limited := headroom[deviceType]; limited && count > left {
+			return false
+		}
+	}
+	return true
+}
+
+// Devices per type picked on the node for the claims of a group
+func (d *Driver) pendingGroupUsage(group []string, node string) mycrd.MydeviceUsage {
+	usage := mycrd.MydeviceUsage{}
+	for _, groupClaimUID := range group {
+		request := d.PendingClaimRequests.Get(groupClaimUID, node)
+		usage[claimDeviceType(&request.Spec)] += len(request.Mydevices)
+	}
+	return usage
+}
+
+// Write current usage to the status of all quotas in the namespace
+func (d *Driver) syncQuotaStatus(ctx context.Context, namespace string) error {
+	quotas, err := d.quotaLister.MydeviceQuotas(namespace).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error listing MydeviceQuotas: %v", err)
+	}
+	if len(quotas) == 0 {
+		return nil
+	}
+
+	used, err := d.namespaceUsage(namespace)
+	if err != nil {
+		return err
+	}
+
+	for _, quota := range quotas {
+		if used.Equal(quota.Status.Used) {
+			continue
+		}
+
+		klog.V(5).Infof("Updating usage of MydeviceQuota %v/%v: %v", namespace, quota.Name, used)
+		quota = quota.DeepCopy()
+		quota.Status.Used = used
+		_, err := d.clientset.DraV1alpha().MydeviceQuotas(namespace).UpdateStatus(ctx, quota, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("error updating status of MydeviceQuota %v/%v: %v", namespace, quota.Name, err)
+		}
+	}
+	return nil
+}
+
+// Periodically refresh usage of all quotas, e.g. for new quotas or orphans freed by the reconciler
+func (d *Driver) RunQuotaStatusSync(ctx context.Context, interval time.Duration) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		quotas, err := d.quotaLister.List(labels.Everything())
+		if err != nil {
+			klog.Errorf("Error listing MydeviceQuotas: %v", err)
+			return
+		}
+
+		namespaces := make(map[string]bool)
+		for _, quota := range quotas {
+			namespaces[quota.Namespace] = true
+		}
+
+		for namespace := range namespaces {
+			d.quotaLock.Get(namespace).Lock()
+			err := d.syncQuotaStatus(ctx, namespace)
+			d.quotaLock.Get(namespace).Unlock()
+			if err != nil {
+				klog.Errorf("Error syncing MydeviceQuota status in namespace %v: %v", namespace, err)
+			}
+		}
+	}, interval)
+}
+
+func quotaExceededReason(exceeded []string) string {
+	return "quota exceeded: " + strings.Join(exceeded, "; ")
+}
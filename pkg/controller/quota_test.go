@@ -0,0 +1,397 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	resourcev1alpha1 "k8s.io/api/resource/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/dynamic-resource-allocation/controller"
+
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+func testQuota(name string, namespace string, limit int) *v1alpha.MydeviceQuota {
+	return &v1alpha.MydeviceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: v1alpha.MydeviceQuotaSpec{
+			Limits: map[v1alpha.MydeviceType]int{mycrd.MydeviceType0: limit},
+		},
+	}
+}
+
+// Record the claim as allocated on the MAS with the devices
+func allocateOnMAS(mas *v1alpha.MydeviceAllocationState, claim *resourcev1alpha1.ResourceClaim, deviceUIDs ...string) {
+	claimUID := string(claim.UID)
+	if mas.Spec.ResourceClaimAllocations == nil {
+		mas.Spec.ResourceClaimAllocations = make(map[string]v1alpha.AllocatedMydevices)
+		mas.Spec.ResourceClaimRequests = make(map[string]v1alpha.RequestedMydevices)
+	}
+	request := v1alpha.RequestedMydevices{
+		Spec:           *countSpec(len(deviceUIDs)),
+		AllocatedCount: len(deviceUIDs),
+	}
+	allocation := v1alpha.AllocatedMydevices{}
+	for _, uid := range deviceUIDs {
+		device := mas.Spec.AllocatableMydevices[uid]
+		request.Mydevices = append(request.Mydevices, v1alpha.RequestedMydevice{UID: uid, MaxSharers: 1})
+		allocation = append(allocation, v1alpha.AllocatedMydevice{UID: uid, Type: device.Type, CDIDevice: device.CDIDevice, MaxSharers: 1})
+	}
+	mas.Spec.ResourceClaimRequests[claimUID] = request
+	mas.Spec.ResourceClaimAllocations[claimUID] = allocation
+
+	result, _ := buildAllocationResult(mas.Name, true, allocation)
+	claim.Status.Allocation = result
+	claim.Status.DriverName = mycrd.ApiGroupName
+}
+
+// Cluster with four devices on node1, two of them allocated to claim "used" in
+// the default namespace and one to a claim in another namespace
+func newQuotaTestCluster(t *testing.T, quotas ...*v1alpha.MydeviceQuota) *testCluster {
+	used := testClaim("used")
+	other := testClaim("other")
+	other.Namespace = "other"
+	mas := testMAS("node1", testDevice("dev0"), testDevice("dev1"), testDevice("dev2"), testDevice("dev3"))
+	allocateOnMAS(mas, used, "dev0", "dev1")
+	allocateOnMAS(mas, other, "dev2")
+
+	objects := []runtime.Object{mas, testClass(), used, other}
+	for _, quota := range quotas {
+		objects = append(objects, quota)
+	}
+	return newTestCluster(t, objects...)
+}
+
+func claimAllocation(name string, spec *mycrd.MydeviceClaimParametersSpec) *controller.ClaimAllocation {
+	return &controller.ClaimAllocation{
+		Claim:           testClaim(name),
+		ClaimParameters: spec,
+		Class:           testClass(),
+		ClassParameters: classSpec(0),
+	}
+}
+
+func TestQuotaExceeded(t *testing.T) {
+	testCases := []struct {
+		name     string
+		quotas   []*v1alpha.MydeviceQuota
+		cas      []*controller.ClaimAllocation
+		exceeded int
+	}{
+		{
+			name:   "no quota",
+			quotas: []*v1alpha.MydeviceQuota{testQuota("quota", "other", 1)},
+			cas:    []*controller.ClaimAllocation{claimAllocation("new", countSpec(4))},
+		},
+		{
+			name:   "fits",
+			quotas: []*v1alpha.MydeviceQuota{testQuota("quota", testClaimNamespace, 3)},
+			cas:    []*controller.ClaimAllocation{claimAllocation("new", countSpec(1))},
+		},
+		{
+			name:     "exceeds",
+			quotas:   []*v1alpha.MydeviceQuota{testQuota("quota", testClaimNamespace, 3)},
+			cas:      []*controller.ClaimAllocation{claimAllocation("new", countSpec(2))},
+			exceeded: 1,
+		},
+		{
+			name:     "claims of a pod together",
+			quotas:   []*v1alpha.MydeviceQuota{testQuota("quota", testClaimNamespace, 4)},
+			cas:      []*controller.ClaimAllocation{claimAllocation("a", countSpec(1)), claimAllocation("b", countSpec(2))},
+			exceeded: 1,
+		},
+		{
+			name:   "allocated claim is not counted twice",
+			quotas: []*v1alpha.MydeviceQuota{testQuota("quota", testClaimNamespace, 2)},
+			cas:    []*controller.ClaimAllocation{claimAllocation("used", countSpec(2))},
+		},
+		{
+			name:   "count range is admitted with its minimum",
+			quotas: []*v1alpha.MydeviceQuota{testQuota("quota", testClaimNamespace, 3)},
+			cas:    []*controller.ClaimAllocation{claimAllocation("new", rangeSpec(1, 4))},
+		},
+		{
+			name:     "count range minimum exceeds",
+			quotas:   []*v1alpha.MydeviceQuota{testQuota("quota", testClaimNamespace, 3)},
+			cas:      []*controller.ClaimAllocation{claimAllocation("new", rangeSpec(2, 4))},
+			exceeded: 1,
+		},
+		{
+			name:     "every exceeded quota",
+			quotas:   []*v1alpha.MydeviceQuota{testQuota("small", testClaimNamespace, 2), testQuota("large", testClaimNamespace, 3), testQuota("huge", testClaimNamespace, 8)},
+			cas:      []*controller.ClaimAllocation{claimAllocation("new", countSpec(2))},
+			exceeded: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := newQuotaTestCluster(t, tc.quotas...).newDriver(t)
+			exceeded, err := d.quotaExceeded(testClaimNamespace, tc.cas)
+			if err != nil {
+				t.Fatalf("quotaExceeded: %v", err)
+			}
+			if len(exceeded) != tc.exceeded {
+				t.Errorf("expected %d exceeded quotas, got %v", tc.exceeded, exceeded)
+			}
+		})
+	}
+}
+
+func TestQuotaHeadroom(t *testing.T) {
+	testCases := []struct {
+		name     string
+		quotas   []*v1alpha.MydeviceQuota
+		excluded []string
+		expected mycrd.MydeviceUsage
+	}{
+		{
+			name:   "no quota",
+			quotas: []*v1alpha.MydeviceQuota{testQuota("quota", "other", 1)},
+		},
+		{
+			name:     "left of limit",
+			quotas:   []*v1alpha.MydeviceQuota{testQuota("quota", testClaimNamespace, 3)},
+			expected: mycrd.MydeviceUsage{mycrd.MydeviceType0: 1},
+		},
+		{
+			name:     "excluded claim",
+			quotas:   []*v1alpha.MydeviceQuota{testQuota("quota", testClaimNamespace, 3)},
+			excluded: []string{"used-uid"},
+			expected: mycrd.MydeviceUsage{mycrd.MydeviceType0: 3},
+		},
+		{
+			name:     "lowered limit",
+			quotas:   []*v1alpha.MydeviceQuota{testQuota("quota", testClaimNamespace, 1)},
+			expected: mycrd.MydeviceUsage{mycrd.MydeviceType0: 0},
+		},
+		{
+			name:     "lowest of quotas",
+			quotas:   []*v1alpha.MydeviceQuota{testQuota("large", testClaimNamespace, 8), testQuota("small", testClaimNamespace, 4)},
+			expected: mycrd.MydeviceUsage{mycrd.MydeviceType0: 2},
+		},
+		{
+			name: "unlimited type",
+			quotas: []*v1alpha.MydeviceQuota{{
+				ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: testClaimNamespace},
+				Spec:       v1alpha.MydeviceQuotaSpec{Limits: map[v1alpha.MydeviceType]int{"type1": 1}},
+			}},
+			expected: mycrd.MydeviceUsage{"type1": 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := newQuotaTestCluster(t, tc.quotas...).newDriver(t)
+			headroom, err := d.quotaHeadroom(testClaimNamespace, tc.excluded...)
+			if err != nil {
+				t.Fatalf("quotaHeadroom: %v", err)
+			}
+			if !reflect.DeepEqual(headroom, tc.expected) {
+				t.Errorf("expected headroom %v, got %v", tc.expected, headroom)
+			}
+		})
+	}
+}
+
+func TestLimitToHeadroom(t *testing.T) {
+	testCases := []struct {
+		name     string
+		specs    []*mycrd.MydeviceClaimParametersSpec
+		headroom mycrd.MydeviceUsage
+		// expected count range per claim
+		expected [][2]int
+	}{
+		{
+			name:     "no quota",
+			specs:    []*mycrd.MydeviceClaimParametersSpec{rangeSpec(1, 4)},
+			expected: [][2]int{{1, 4}},
+		},
+		{
+			name:     "enough headroom",
+			specs:    []*mycrd.MydeviceClaimParametersSpec{rangeSpec(1, 4)},
+			headroom: mycrd.MydeviceUsage{mycrd.MydeviceType0: 4},
+			expected: [][2]int{{1, 4}},
+		},
+		{
+			name:     "range lowered",
+			specs:    []*mycrd.MydeviceClaimParametersSpec{rangeSpec(1, 4)},
+			headroom: mycrd.MydeviceUsage{mycrd.MydeviceType0: 2},
+			expected: [][2]int{{1, 2}},
+		},
+		{
+			name:     "minimum kept",
+			specs:    []*mycrd.MydeviceClaimParametersSpec{rangeSpec(2, 4)},
+			headroom: mycrd.MydeviceUsage{mycrd.MydeviceType0: 0},
+			expected: [][2]int{{2, 2}},
+		},
+		{
+			name:     "exact count untouched",
+			specs:    []*mycrd.MydeviceClaimParametersSpec{countSpec(3)},
+			headroom: mycrd.MydeviceUsage{mycrd.MydeviceType0: 1},
+			expected: [][2]int{{3, 3}},
+		},
+		{
+			name:     "minimums of all claims first, earlier claims get the rest",
+			specs:    []*mycrd.MydeviceClaimParametersSpec{rangeSpec(1, 3), countSpec(1), rangeSpec(1, 3)},
+			headroom: mycrd.MydeviceUsage{mycrd.MydeviceType0: 5},
+			expected: [][2]int{{1, 3}, {1, 1}, {1, 1}},
+		},
+		{
+			name:     "unlimited type",
+			specs:    []*mycrd.MydeviceClaimParametersSpec{rangeSpec(1, 4)},
+			headroom: mycrd.MydeviceUsage{"type1": 0},
+			expected: [][2]int{{1, 4}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cas := []*controller.ClaimAllocation{}
+			originals := []mycrd.MydeviceClaimParametersSpec{}
+			for i, spec := range tc.specs {
+				cas = append(cas, claimAllocation(fmt.Sprintf("claim%d", i), spec))
+				originals = append(originals, *spec)
+			}
+
+			limitToHeadroom(cas, tc.headroom)
+
+			for i, ca := range cas {
+				minCount, maxCount := mycrd.CountRange(ca.ClaimParameters.(*mycrd.MydeviceClaimParametersSpec))
+				if minCount != tc.expected[i][0] || maxCount != tc.expected[i][1] {
+					t.Errorf("claim %d: expected count range %v, got [%d %d]", i, tc.expected[i], minCount, maxCount)
+				}
+				// parameters may be shared with other claims, they are copied before they change
+				if !reflect.DeepEqual(*tc.specs[i], originals[i]) {
+					t.Errorf("claim %d: parameters modified in place", i)
+				}
+			}
+		})
+	}
+}
+
+func TestAllocateQuotaAdmission(t *testing.T) {
+	testCases := []struct {
+		name            string
+		spec            *mycrd.MydeviceClaimParametersSpec
+		expectedDevices int
+		expectedError   string
+	}{
+		{
+			name:            "fits",
+			spec:            countSpec(1),
+			expectedDevices: 1,
+		},
+		{
+			name:          "exceeds",
+			spec:          countSpec(2),
+			expectedError: "quota exceeded",
+		},
+		{
+			name:            "count range admitted with its minimum gets the headroom",
+			spec:            rangeSpec(1, 2),
+			expectedDevices: 1,
+		},
+		{
+			name:          "count range minimum exceeds",
+			spec:          rangeSpec(2, 2),
+			expectedError: "quota exceeded",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			claim := testClaim("new")
+			c := newQuotaTestCluster(t, testQuota("quota", testClaimNamespace, 3))
+			if err := c.add(claim); err != nil {
+				t.Fatal(err)
+			}
+			d := c.newDriver(t)
+
+			_, err := d.Allocate(context.TODO(), claim, tc.spec, testClass(), classSpec(0), "")
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("expected error %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Allocate: %v", err)
+			}
+
+			devices := c.getMAS(t, "node1").Spec.ResourceClaimAllocations["new-uid"]
+			if len(devices) != tc.expectedDevices {
+				t.Errorf("expected %d devices, got %v", tc.expectedDevices, devices)
+			}
+
+			quota, err := c.exampleclient.DraV1alpha().MydeviceQuotas(testClaimNamespace).Get(context.TODO(), "quota", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if used := quota.Status.Used[mycrd.MydeviceType0]; used != 2+tc.expectedDevices {
+				t.Errorf("expected quota status to count %d devices, got %d", 2+tc.expectedDevices, used)
+			}
+		})
+	}
+}
+
+// Allocate takes the namespace quota lock before the node lock, Deallocate must
+// not wait for the quota lock while it holds the node lock
+func TestDeallocateReleasesNodeLockBeforeQuotaLock(t *testing.T) {
+	claim := testClaim("allocated")
+	mas := testMAS("node1", testDevice("dev0"))
+	allocateOnMAS(mas, claim, "dev0")
+	c := newTestCluster(t, mas, testClass(), claim, testQuota("quota", testClaimNamespace, 1))
+	d := c.newDriver(t)
+
+	// as Allocate does before it looks at the nodes
+	d.quotaLock.Get(testClaimNamespace).Lock()
+	done := make(chan error)
+	go func() {
+		done <- d.Deallocate(context.TODO(), claim)
+	}()
+
+	err := wait.PollImmediate(10*time.Millisecond, 10*time.Second, func() (bool, error) {
+		_, allocated := c.getMAS(t, "node1").Spec.ResourceClaimAllocations["allocated-uid"]
+		return !allocated, nil
+	})
+	if err != nil {
+		t.Fatalf("claim not deallocated: %v", err)
+	}
+	nodeLock := d.lock.Get("node1")
+	if !nodeLock.TryLock() {
+		t.Fatal("Deallocate holds the node lock while it waits for the quota lock")
+	}
+	nodeLock.Unlock()
+
+	d.quotaLock.Get(testClaimNamespace).Unlock()
+	if err := <-done; err != nil {
+		t.Fatalf("Deallocate: %v", err)
+	}
+}
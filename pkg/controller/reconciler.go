@@ -0,0 +1,153 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+// OrphanReconciler frees MAS allocations whose ResourceClaim no longer exists or
+// is no longer allocated on that node, e.g. when a claim was force-deleted and
+// Deallocate was never called.
+//
+// The MAS is written before the claim status, and the claim informer may lag behind,
+// so an allocation is only freed once it was found orphaned in two consecutive passes.
+type OrphanReconciler struct {
+	driver *Driver
+	dryRun bool
+	// claim UID -> node, orphans found in the previous pass
+	suspects map[string]string
+}
+
+func NewOrphanReconciler(d *Driver, dryRun bool) *OrphanReconciler {
+	return &OrphanReconciler{
+		driver:   d,
+		dryRun:   dryRun,
+		suspects: make(map[string]string),
+	}
+}
+
+func (r *OrphanReconciler) Run(ctx context.Context, interval time.Duration) {
+	klog.V(3).Infof("Starting orphaned allocation reconciler with interval %v, dry-run: %v", interval, r.dryRun)
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		err := r.reconcile()
+		if err != nil {
+			klog.Errorf("Error reconciling orphaned allocations: %v", err)
+		}
+	}, interval)
+}
+
+func (r *OrphanReconciler) reconcile() error {
+	masnames, err := r.driver.listMASNames()
+	if err != nil {
+		return fmt.Errorf("error listing MAS objects: %v", err)
+	}
+
+	suspects := make(map[string]string)
+	for _, nodename := range masnames {
+		err := r.reconcileNode(nodename, suspects)
+		if err != nil {
+			klog.Errorf("Error reconciling allocations on node %v: %v", nodename, err)
+		}
+	}
+	r.suspects = suspects
+
+	return nil
+}
+
+// Find orphaned allocations on the node, free those that were already suspected in
+// the previous pass and record the rest in suspects.
+func (r *OrphanReconciler) reconcileNode(nodename string, suspects map[string]string) error {
+	d := r.driver
+	d.lock.Get(nodename).Lock()
+	defer d.lock.Get(nodename).Unlock()
+
+	mas, err := d.getMAS(nodename)
+	if err != nil {
+		return err
+	}
+
+	orphans := []string{}
+	for claimUID := range mas.Spec.ResourceClaimAllocations {
+		reason := r.orphanReason(claimUID, nodename)
+		if reason == "" {
+			continue
+		}
+
+		if r.suspects[claimUID] != nodename {
+			klog.V(3).Infof("Allocation of claim %v on node %v looks orphaned (%v), checking again in next pass", claimUID, nodename, reason)
+			suspects[claimUID] = nodename
+			continue
+		}
+
+		if r.dryRun {
+			klog.Infof("Allocation of claim %v on node %v is orphaned (%v), not freeing in dry-run mode", claimUID, nodename, reason)
+			suspects[claimUID] = nodename
+			continue
+		}
+
+		klog.Infof("Freeing orphaned allocation of claim %v on node %v: %v", claimUID, nodename, reason)
+		orphans = append(orphans, claimUID)
+	}
+
+	if len(orphans) == 0 {
+		return nil
+	}
+
+	err = d.updateMASWithRetry(mas, func(mas *mycrd.MydeviceAllocationState) error {
+		for _, claimUID := range orphans {
+			// allocation may have been made legitimately again since the check above
+			if r.orphanReason(claimUID, nodename) == "" {
+				continue
+			}
+			delete(mas.Spec.ResourceClaimAllocations, claimUID)
+			delete(mas.Spec.ResourceClaimRequests, claimUID)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error updating MydeviceAllocationState CRD: %v", err)
+	}
+
+	for _, claimUID := range orphans {
+		d.PendingClaimRequests.Remove(claimUID)
+	}
+
+	return nil
+}
+
+// Returns why the allocation of the claim on the node is orphaned, or empty string if it is not.
+func (r *OrphanReconciler) orphanReason(claimUID, nodename string) string {
+	claim, exists := r.driver.getClaimByUID(claimUID)
+	if !exists {
+		return "ResourceClaim does not exist"
+	}
+	if claim.Status.Allocation == nil {
+		return fmt.Sprintf("ResourceClaim %v/%v is not allocated", claim.Namespace, claim.Name)
+	}
+	if selectedNode := getSelectedNode(claim); selectedNode != nodename {
+		return fmt.Sprintf("ResourceClaim %v/%v is allocated on node '%v'", claim.Namespace, claim.Name, selectedNode)
+	}
+	return ""
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+	"k8s.io/klog/v2"
+)
+
+/*
+	PerNodeClaimRequests is a map {
+		claim-uid : map {
+			nodename : pendingClaimRequest{
+							devices: mycrd.RequestedMydevices{
+								Spec    MydeviceClaimParametersSpec `json:"spec"`
+								Devices []RequestedMydevice         `json:"devices"`
+							}
+							victims: []string
+							siblings: []string
+							expires: time.Time
+						}
+			}
+		}
+	}
+*/
+type PerNodeClaimRequests struct {
+	sync.RWMutex
+	ttl      time.Duration
+	requests map[string]map[string]pendingClaimRequest
+}
+
+// pendingClaimRequest is a tentative device pick, refreshed every time it is Set
+type pendingClaimRequest struct {
+	devices mycrd.RequestedMydevices
+	// claims to preempt before the devices are free
+	victims []string
+	// other claims of the same pod, allocated together with this one
+	siblings []string
+	expires  time.Time
+}
+
+func (r pendingClaimRequest) expired(now time.Time) bool {
+	return now.After(r.expires)
+}
+
+func NewPerNodeClaimRequests(ttl time.Duration) *PerNodeClaimRequests {
+	return &PerNodeClaimRequests{
+		ttl:      ttl,
+		requests: make(map[string]map[string]pendingClaimRequest),
+	}
+}
+
+func (p *PerNodeClaimRequests) Exists(claimUID, node string) bool {
+	p.RLock()
+	defer p.RUnlock()
+
+	_, exists := p.get(claimUID, node)
+	return exists
+}
+
+func (p *PerNodeClaimRequests) Get(claimUID, node string) mycrd.RequestedMydevices {
+	p.RLock()
+	defer p.RUnlock()
+
+	request, exists := p.get(claimUID, node)
+	if !exists {
+		return mycrd.RequestedMydevices{}
+	}
+	return request.devices
+}
+
+// get must be called with the lock held, expired requests are treated as missing
+func (p *PerNodeClaimRequests) get(claimUID, node string) (pendingClaimRequest, bool) {
+	request, exists := p.requests[claimUID][node]
+	if !exists || request.expired(time.Now()) {
+		return pendingClaimRequest{}, false
+	}
+	return request, true
+}
+
+func (p *PerNodeClaimRequests) CleanupNode(mas *mycrd.MydeviceAllocationState) {
+	p.Lock()
+	defer p.Unlock()
+
+	for claimUID := range p.requests {
+		if request, exists := p.get(claimUID, mas.Name); exists {
+			klog.V(5).Infof("Cleaning up resource requests for node %v", mas.Name)
+			// cleanup processed claim requests, pending ones are in the MAS too
+			if _, exists := mas.Spec.ResourceClaimAllocations[claimUID]; exists {
+				delete(p.requests, claimUID)
+			} else {
+				mas.Spec.ResourceClaimRequests[claimUID] = request.devices
+			}
+		}
+	}
+}
+
+func (p *PerNodeClaimRequests) Set(claimUID, node string, devices mycrd.RequestedMydevices) {
+	p.Lock()
+	defer p.Unlock()
+
+	_, exists := p.requests[claimUID]
+	if !exists {
+		p.requests[claimUID] = make(map[string]pendingClaimRequest)
+	}
+
+	p.requests[claimUID][node] = pendingClaimRequest{
+		devices: devices,
+		expires: time.Now().Add(p.ttl),
+	}
+}
+
+// SetVictims records claims that have to be preempted for the request on the node to fit
+func (p *PerNodeClaimRequests) SetVictims(claimUID, node string, victims []string) {
+	p.Lock()
+	defer p.Unlock()
+
+	request, exists := p.get(claimUID, node)
+	if !exists {
+		return
+	}
+	request.victims = victims
+	p.requests[claimUID][node] = request
+}
+
+func (p *PerNodeClaimRequests) GetVictims(claimUID, node string) []string {
+	p.RLock()
+	defer p.RUnlock()
+
+	request, exists := p.get(claimUID, node)
+	if !exists {
+		return nil
+	}
+	return request.victims
+}
+
+// SetSiblings records the other claims of the pod that were fitted on the node together with the claim
+func (p *PerNodeClaimRequests) SetSiblings(claimUID, node string, siblings []string) {
+	p.Lock()
+	defer p.Unlock()
+
+	request, exists := p.get(claimUID, node)
+	if !exists {
+		return
+	}
+	request.siblings = siblings
+	p.requests[claimUID][node] = request
+}
+
+func (p *PerNodeClaimRequests) GetSiblings(claimUID, node string) []string {
+	p.RLock()
+	defer p.RUnlock()
+
+	request, exists := p.get(claimUID, node)
+	if !exists {
+		return nil
+	}
+	return request.siblings
+}
+
+// PreemptionNode returns the node where claims are preempted for the claim, as
+// long as its request there has not expired
+func (p *PerNodeClaimRequests) PreemptionNode(claimUID string) (string, bool) {
+	p.RLock()
+	defer p.RUnlock()
+
+	for node := range p.requests[claimUID] {
+		if request, exists := p.get(claimUID, node); exists && len(request.victims) > 0 {
+			return node, true
+		}
+	}
+	return "", false
+}
+
+func (p *PerNodeClaimRequests) Remove(claimUID string) {
+	p.Lock()
+	defer p.Unlock()
+
+	delete(p.requests, claimUID)
+}
+
+// Collect drops expired requests and all requests of claims for which stale returns true.
+// stale is called with the lock held and must not call back into PerNodeClaimRequests.
+func (p *PerNodeClaimRequests) Collect(stale func(claimUID string) bool) int {
+	p.Lock()
+	defer p.Unlock()
+
+	now := time.Now()
+	removed := 0
+	for claimUID, nodes := range p.requests {
+		if stale(claimUID) {
+			removed += len(nodes)
+			delete(p.requests, claimUID)
+			continue
+		}
+
+		for node, request := range nodes {
+			if request.expired(now) {
+				removed++
+				delete(nodes, node)
+			}
+		}
+		if len(nodes) == 0 {
+			delete(p.requests, claimUID)
+		}
+	}
+	return removed
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/klog/v2"
+
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha/api"
+)
+
+// Returns the topology domain of the device within scope, empty if unknown
+func topologyKey(device *mycrd.AllocatableMydevice, scope mycrd.TopologyScope) string {
+	if device.Topology == nil {
+		return ""
+	}
+
+	switch scope {
+	case mycrd.TopologyScopeNUMANode:
+		if device.Topology.NUMANode < 0 {
+			return ""
+		}
+		return fmt.Sprintf("%d", device.Topology.NUMANode)
+	case mycrd.TopologyScopePCIRoot:
+		return device.Topology.PCIRoot
+	case mycrd.TopologyScopePCISwitch:
+		if device.Topology.ParentBridge == "" {
+			return ""
+		}
+		return device.Topology.PCIRoot + "/" + device.Topology.ParentBridge
+	default:
+		return ""
+	}
+}
+
+// Pick count devices out of candidates honoring the claim topology constraint.
+// Returns fewer devices than requested if the constraint cannot be satisfied.
+func pickDevices(
+	candidates []*mycrd.AllocatableMydevice,
+	count int,
+	constraint *mycrd.MydeviceTopologyConstraint) []*mycrd.AllocatableMydevice {
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].UID < candidates[j].UID
+	})
+
+	if constraint == nil || count < 2 {
+		if len(candidates) > count {
+			return candidates[:count]
+		}
+		return candidates
+	}
+
+	domains := make(map[string][]*mycrd.AllocatableMydevice)
+	for _, device := range candidates {
+		key := topologyKey(device, constraint.Scope)
+		domains[key] = append(domains[key], device)
+	}
+
+	// best fit: smallest known domain that has enough devices, keeps larger domains for larger claims
+	var keys []string
+	for key, devices := range domains {
+		if key != "" && len(devices) >= count {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) > 0 {
+		sort.Slice(keys, func(i, j int) bool {
+			if len(domains[keys[i]]) != len(domains[keys[j]]) {
+				return len(domains[keys[i]]) < len(domains[keys[j]])
+			}
+			return keys[i] < keys[j]
+		})
+		klog.V(5).Infof("Picking devices from %v topology domain %v", constraint.Scope, keys[0])
+		return domains[keys[0]][:count]
+	}
+
+	if constraint.Policy == mycrd.TopologyPolicyRequire {
+		klog.V(5).Infof("No %v topology domain has %d devices available", constraint.Scope, count)
+		return nil
+	}
+
+	// preferred only: fill from largest domains first to keep the spread low
+	keys = nil
+	for key := range domains {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if (keys[i] == "") != (keys[j] == "") {
+			return keys[j] == ""
+		}
+		if len(domains[keys[i]]) != len(domains[keys[j]]) {
+			return len(domains[keys[i]]) > len(domains[keys[j]])
+		}
+		return keys[i] < keys[j]
+	})
+
+	picked := []*mycrd.AllocatableMydevice{}
+	for _, key := range keys {
+		for _, device := range domains[key] {
+			if len(picked) == count {
+				return picked
+			}
+			picked = append(picked, device)
+		}
+	}
+	return picked
+}
+
+// Pick as many devices as possible within the count range, preferring maxCount.
+// Returns fewer than minCount devices if the claim does not fit.
+func pickDevicesInRange(
+	candidates []*mycrd.AllocatableMydevice,
+	minCount, maxCount int,
+	constraint *mycrd.MydeviceTopologyConstraint) []*mycrd.AllocatableMydevice {
+	for count := maxCount; count > minCount; count-- {
+		if picked := pickDevices(candidates, count, constraint); len(picked) == count {
+			return picked
+		}
+	}
+	return pickDevices(candidates, minCount, constraint)
+}
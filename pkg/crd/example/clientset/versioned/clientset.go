@@ -23,6 +23,7 @@ import (
 	"net/http"
 
 	drav1alpha "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/clientset/versioned/typed/example/v1alpha"
+	drav1beta1 "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/clientset/versioned/typed/example/v1beta1"
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
 	flowcontrol "k8s.io/client-go/util/flowcontrol"
@@ -31,12 +32,14 @@ import (
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	DraV1alpha() drav1alpha.DraV1alphaInterface
+	DraV1beta1() drav1beta1.DraV1beta1Interface
 }
 
 // Clientset contains the clients for groups.
 type Clientset struct {
 	*discovery.DiscoveryClient
 	draV1alpha *drav1alpha.DraV1alphaClient
+	draV1beta1 *drav1beta1.DraV1beta1Client
 }
 
 // DraV1alpha retrieves the DraV1alphaClient
@@ -44,6 +47,11 @@ func (c *Clientset) DraV1alpha() drav1alpha.DraV1alphaInterface {
 	return c.draV1alpha
 }
 
+// DraV1beta1 retrieves the DraV1beta1Client
+func (c *Clientset) DraV1beta1() drav1beta1.DraV1beta1Interface {
+	return c.draV1beta1
+}
+
 // Discovery retrieves the DiscoveryClient
 func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 	if c == nil {
@@ -92,6 +100,10 @@ func NewForConfigAndClient(c *rest.Config, httpClient *http.Client) (*Clientset,
 	if err != nil {
 		return nil, err
 	}
+	cs.draV1beta1, err = drav1beta1.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfigAndClient(&configShallowCopy, httpClient)
 	if err != nil {
@@ -114,6 +126,7 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.draV1alpha = drav1alpha.New(c)
+	cs.draV1beta1 = drav1beta1.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
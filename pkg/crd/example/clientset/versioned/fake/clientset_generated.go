@@ -22,6 +22,8 @@ import (
 	clientset "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/clientset/versioned"
 	drav1alpha "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/clientset/versioned/typed/example/v1alpha"
 	fakedrav1alpha "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/clientset/versioned/typed/example/v1alpha/fake"
+	drav1beta1 "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/clientset/versioned/typed/example/v1beta1"
+	fakedrav1beta1 "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/clientset/versioned/typed/example/v1beta1/fake"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
@@ -83,3 +85,8 @@ var (
 func (c *Clientset) DraV1alpha() drav1alpha.DraV1alphaInterface {
 	return &fakedrav1alpha.FakeDraV1alpha{Fake: &c.Fake}
 }
+
+// DraV1beta1 retrieves the DraV1beta1Client
+func (c *Clientset) DraV1beta1() drav1beta1.DraV1beta1Interface {
+	return &fakedrav1beta1.FakeDraV1beta1{Fake: &c.Fake}
+}
@@ -20,6 +20,7 @@ package scheme
 
 import (
 	drav1alpha "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+	drav1beta1 "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -32,6 +33,7 @@ var Codecs = serializer.NewCodecFactory(Scheme)
 var ParameterCodec = runtime.NewParameterCodec(Scheme)
 var localSchemeBuilder = runtime.SchemeBuilder{
 	drav1alpha.AddToScheme,
+	drav1beta1.AddToScheme,
 }
 
 // AddToScheme adds all types of this clientset into the given scheme. This allows composition
@@ -31,6 +31,7 @@ type DraV1alphaInterface interface {
 	MydeviceAllocationStatesGetter
 	MydeviceClaimParametersGetter
 	MydeviceClassParametersGetter
+	MydeviceQuotasGetter
 }
 
 // DraV1alphaClient is used to interact with features provided by the dra.example.com group.
@@ -50,6 +51,10 @@ func (c *DraV1alphaClient) MydeviceClassParameters() MydeviceClassParametersInte
 	return newMydeviceClassParameters(c)
 }
 
+func (c *DraV1alphaClient) MydeviceQuotas(namespace string) MydeviceQuotaInterface {
+	return newMydeviceQuotas(c, namespace)
+}
+
 // NewForConfig creates a new DraV1alphaClient for the given config.
 // NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
 // where httpClient was generated with rest.HTTPClientFor(c).
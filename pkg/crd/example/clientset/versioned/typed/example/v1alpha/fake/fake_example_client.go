@@ -40,6 +40,10 @@ func (c *FakeDraV1alpha) MydeviceClassParameters() v1alpha.MydeviceClassParamete
 	return &FakeMydeviceClassParameters{c}
 }
 
+func (c *FakeDraV1alpha) MydeviceQuotas(namespace string) v1alpha.MydeviceQuotaInterface {
+	return &FakeMydeviceQuotas{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeDraV1alpha) RESTClient() rest.Interface {
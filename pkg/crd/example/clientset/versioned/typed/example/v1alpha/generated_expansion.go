@@ -23,3 +23,5 @@ type MydeviceAllocationStateExpansion interface{}
 type MydeviceClaimParametersExpansion interface{}
 
 type MydeviceClassParametersExpansion interface{}
+
+type MydeviceQuotaExpansion interface{}
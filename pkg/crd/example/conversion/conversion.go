@@ -0,0 +1,128 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1beta1"
+)
+
+var (
+	v1alphaAPIVersion = v1alpha.SchemeGroupVersion.String()
+	v1beta1APIVersion = v1beta1.SchemeGroupVersion.String()
+)
+
+// Convert converts a JSON encoded object of this API group to desiredAPIVersion.
+// Objects already in the desired version are returned as they are.
+func Convert(raw []byte, desiredAPIVersion string) ([]byte, error) {
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, fmt.Errorf("could not decode object type: %v", err)
+	}
+
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return raw, nil
+	}
+
+	var out interface{}
+	var err error
+	switch {
+	case typeMeta.APIVersion == v1alphaAPIVersion && desiredAPIVersion == v1beta1APIVersion:
+		out, err = convertToV1beta1(raw, typeMeta.Kind)
+	case typeMeta.APIVersion == v1beta1APIVersion && desiredAPIVersion == v1alphaAPIVersion:
+		out, err = convertToV1alpha(raw, typeMeta.Kind)
+	default:
+		err = fmt.Errorf("unsupported conversion from %v to %v", typeMeta.APIVersion, desiredAPIVersion)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(out)
+}
+
+func convertToV1beta1(raw []byte, kind string) (interface{}, error) {
+	switch kind {
+	case "MydeviceAllocationState":
+		in := &v1alpha.MydeviceAllocationState{}
+		if err := json.Unmarshal(raw, in); err != nil {
+			return nil, fmt.Errorf("could not decode %v: %v", kind, err)
+		}
+		return MydeviceAllocationStateToV1beta1(in), nil
+	case "MydeviceClaimParameters":
+		in := &v1alpha.MydeviceClaimParameters{}
+		if err := json.Unmarshal(raw, in); err != nil {
+			return nil, fmt.Errorf("could not decode %v: %v", kind, err)
+		}
+		return MydeviceClaimParametersToV1beta1(in), nil
+	case "MydeviceClassParameters":
+		in := &v1alpha.MydeviceClassParameters{}
+		if err := json.Unmarshal(raw, in); err != nil {
+			return nil, fmt.Errorf("could not decode %v: %v", kind, err)
+		}
+		return MydeviceClassParametersToV1beta1(in), nil
+	case "MydeviceQuota":
+		in := &v1alpha.MydeviceQuota{}
+		if err := json.Unmarshal(raw, in); err != nil {
+			return nil, fmt.Errorf("could not decode %v: %v", kind, err)
+		}
+		return MydeviceQuotaToV1beta1(in), nil
+	}
+	return nil, fmt.Errorf("unsupported kind: %v", kind)
+}
+
+func convertToV1alpha(raw []byte, kind string) (interface{}, error) {
+	switch kind {
+	case "MydeviceAllocationState":
+		in := &v1beta1.MydeviceAllocationState{}
+		if err := json.Unmarshal(raw, in); err != nil {
+			return nil, fmt.Errorf("could not decode %v: %v", kind, err)
+		}
+		return MydeviceAllocationStateToV1alpha(in), nil
+	case "MydeviceClaimParameters":
+		in := &v1beta1.MydeviceClaimParameters{}
+		if err := json.Unmarshal(raw, in); err != nil {
+			return nil, fmt.Errorf("could not decode %v: %v", kind, err)
+		}
+		return MydeviceClaimParametersToV1alpha(in), nil
+	case "MydeviceClassParameters":
+		in := &v1beta1.MydeviceClassParameters{}
+		if err := json.Unmarshal(raw, in); err != nil {
+			return nil, fmt.Errorf("could not decode %v: %v", kind, err)
+		}
+		return MydeviceClassParametersToV1alpha(in), nil
+	case "MydeviceQuota":
+		in := &v1beta1.MydeviceQuota{}
+		if err := json.Unmarshal(raw, in); err != nil {
+			return nil, fmt.Errorf("could not decode %v: %v", kind, err)
+		}
+		return MydeviceQuotaToV1alpha(in), nil
+	}
+	return nil, fmt.Errorf("unsupported kind: %v", kind)
+}
+
+func typeMeta(kind, apiVersion string) metav1.TypeMeta {
+	return metav1.TypeMeta{
+		Kind:       kind,
+		APIVersion: apiVersion,
+	}
+}
@@ -0,0 +1,382 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1beta1"
+)
+
+// roundTrip converts in to the other version and back through Convert, as the
+// conversion webhook does, and returns the result together with the intermediate object
+func roundTrip[T any](t *testing.T, in *T, via string) (*T, []byte) {
+	t.Helper()
+
+	raw, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		t.Fatal(err)
+	}
+
+	converted, err := Convert(raw, via)
+	if err != nil {
+		t.Fatalf("converting to %v: %v", via, err)
+	}
+	convertedTypeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(converted, &convertedTypeMeta); err != nil {
+		t.Fatal(err)
+	}
+	if convertedTypeMeta.APIVersion != via || convertedTypeMeta.Kind != typeMeta.Kind {
+		t.Fatalf("expected %v %v, got %v %v", via, typeMeta.Kind, convertedTypeMeta.APIVersion, convertedTypeMeta.Kind)
+	}
+
+	back, err := Convert(converted, typeMeta.APIVersion)
+	if err != nil {
+		t.Fatalf("converting back to %v: %v", typeMeta.APIVersion, err)
+	}
+	out := new(T)
+	if err := json.Unmarshal(back, out); err != nil {
+		t.Fatal(err)
+	}
+	return out, converted
+}
+
+// normalize drops what does not survive JSON encoding anyway, like sub-second times
+func normalize[T any](t *testing.T, in *T) *T {
+	t.Helper()
+	raw, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := new(T)
+	if err := json.Unmarshal(raw, out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func expectRoundTrip[T any](t *testing.T, in *T, via string) []byte {
+	t.Helper()
+	out, converted := roundTrip(t, in, via)
+	if expected := normalize(t, in); !reflect.DeepEqual(out, expected) {
+		t.Errorf("round trip through %v changed the object\nexpected: %+v\ngot:      %+v\nvia:      %s", via, expected, out, converted)
+	}
+	return converted
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func testObjectMeta(name, namespace string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:              name,
+		Namespace:         namespace,
+		UID:               types.UID("uid-" + name),
+		Generation:        3,
+		CreationTimestamp: metav1.NewTime(time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)),
+		Labels:            map[string]string{"app": "test"},
+		Annotations:       map[string]string{"note": "kept"},
+	}
+}
+
+func v1alphaClaimParametersSpec() v1alpha.MydeviceClaimParametersSpec {
+	return v1alpha.MydeviceClaimParametersSpec{
+		MinCount: 2,
+		MaxCount: 4,
+		Type:     v1alpha.MydeviceType0,
+		Priority: int32Ptr(-5),
+		Selector: &v1alpha.MydeviceClaimSelector{
+			VendorID:         "0x8086",
+			DeviceID:         "0x56a0",
+			PCIAddressPrefix: "0000:03",
+		},
+		Topology: &v1alpha.MydeviceTopologyConstraint{
+			Scope:  v1alpha.TopologyScopePCISwitch,
+			Policy: v1alpha.TopologyPolicyRequire,
+		},
+	}
+}
+
+func v1alphaMAS(status string) *v1alpha.MydeviceAllocationState {
+	return &v1alpha.MydeviceAllocationState{
+		TypeMeta:   metav1.TypeMeta{Kind: "MydeviceAllocationState", APIVersion: v1alphaAPIVersion},
+		ObjectMeta: testObjectMeta("node1", "dra-example-driver"),
+		Spec: v1alpha.MydeviceAllocationStateSpec{
+			AllocatableMydevices: map[string]v1alpha.AllocatableMydevice{
+				"0000:03:00.0-0x56a0": {
+					UID:        "0000:03:00.0-0x56a0",
+					Type:       v1alpha.MydeviceType0,
+					CDIDevice:  "example.com/mydevice=0000:03:00.0-0x56a0",
+					VendorID:   "0x8086",
+					DeviceID:   "0x56a0",
+					PCIAddress: "0000:03:00.0",
+					MaxSharers: 2,
+					Topology:   &v1alpha.MydeviceTopology{NUMANode: 1, PCIRoot: "pci0000:00", ParentBridge: "0000:02:00.0"},
+					Health:     v1alpha.MydeviceUnhealthy,
+					Cordoned:   true,
+				},
+				"0000:04:00.0-0x56a0": {
+					UID:       "0000:04:00.0-0x56a0",
+					Type:      v1alpha.MydeviceType0,
+					CDIDevice: "example.com/mydevice=0000:04:00.0-0x56a0",
+					Topology:  &v1alpha.MydeviceTopology{NUMANode: -1},
+				},
+			},
+			ResourceClaimAllocations: map[string]v1alpha.AllocatedMydevices{
+				"claim-b": {{UID: "0000:03:00.0-0x56a0", Type: v1alpha.MydeviceType0, CDIDevice: "example.com/mydevice=0000:03:00.0-0x56a0", MaxSharers: 2}},
+				"claim-a": {{UID: "0000:03:00.0-0x56a0", Type: v1alpha.MydeviceType0, CDIDevice: "example.com/mydevice=0000:03:00.0-0x56a0", MaxSharers: 2}},
+			},
+			ResourceClaimRequests: map[string]v1alpha.RequestedMydevices{
+				"claim-c": {
+					Spec:           v1alphaClaimParametersSpec(),
+					Mydevices:      []v1alpha.RequestedMydevice{{UID: "0000:04:00.0-0x56a0", MaxSharers: 1}},
+					AllocatedCount: 1,
+					Siblings:       []string{"claim-d"},
+				},
+			},
+		},
+		Status: status,
+	}
+}
+
+func TestMydeviceAllocationStateRoundTrip(t *testing.T) {
+	for _, status := range []string{"", v1alphaStatusReady, v1alphaStatusNotReady, "Degraded"} {
+		t.Run("status "+status, func(t *testing.T) {
+			converted := expectRoundTrip(t, v1alphaMAS(status), v1beta1APIVersion)
+
+			mas := &v1beta1.MydeviceAllocationState{}
+			if err := json.Unmarshal(converted, mas); err != nil {
+				t.Fatal(err)
+			}
+			if len(mas.Spec.Allocations) != 2 || mas.Spec.Allocations[0].ClaimUID != "claim-a" {
+				t.Errorf("expected allocations sorted by claim UID, got %+v", mas.Spec.Allocations)
+			}
+			if attributes := mas.Spec.AllocatableDevices[0].Attributes; attributes[v1beta1.AttributePCIAddress] != "0000:03:00.0" {
+				t.Errorf("expected PCI address attribute, got %v", attributes)
+			}
+		})
+	}
+
+	empty := &v1alpha.MydeviceAllocationState{
+		TypeMeta:   metav1.TypeMeta{Kind: "MydeviceAllocationState", APIVersion: v1alphaAPIVersion},
+		ObjectMeta: metav1.ObjectMeta{Name: "node1", Namespace: "dra-example-driver"},
+	}
+	t.Run("empty", func(t *testing.T) {
+		expectRoundTrip(t, empty, v1beta1APIVersion)
+	})
+}
+
+// v1beta1 fields without v1alpha counterpart are kept in the v1beta1-data annotation
+func TestMydeviceAllocationStateV1beta1DataAnnotation(t *testing.T) {
+	transition := metav1.NewTime(time.Date(2023, 6, 1, 8, 30, 0, 0, time.UTC))
+	in := &v1beta1.MydeviceAllocationState{
+		TypeMeta:   metav1.TypeMeta{Kind: "MydeviceAllocationState", APIVersion: v1beta1APIVersion},
+		ObjectMeta: testObjectMeta("node1", "dra-example-driver"),
+		Spec: v1beta1.MydeviceAllocationStateSpec{
+			AllocatableDevices: []v1beta1.AllocatableMydevice{
+				{
+					UID:       "dev0",
+					Type:      v1beta1.MydeviceType0,
+					CDIDevice: "example.com/mydevice=dev0",
+					Attributes: map[string]string{
+						v1beta1.AttributeVendorID: "0x8086",
+						"firmware":                "1.2.3",
+						"memory":                  "16Gi",
+					},
+				},
+				{
+					UID:       "dev1",
+					Type:      v1beta1.MydeviceType0,
+					CDIDevice: "example.com/mydevice=dev1",
+				},
+			},
+			Allocations: []v1beta1.MydeviceClaimAllocation{
+				{ClaimUID: "claim-a", Devices: []v1beta1.AllocatedMydevice{{UID: "dev0", Type: v1beta1.MydeviceType0, CDIDevice: "example.com/mydevice=dev0"}}},
+			},
+		},
+		Status: v1beta1.MydeviceAllocationStateStatus{
+			Conditions: []metav1.Condition{
+				{Type: "HealthChecked", Status: metav1.ConditionTrue, Reason: "Checked", Message: "all devices checked", LastTransitionTime: transition},
+				{Type: v1beta1.MydeviceAllocationStateReady, Status: metav1.ConditionFalse, Reason: "PluginRestarting", Message: "plugin is restarting", ObservedGeneration: 3, LastTransitionTime: transition},
+			},
+		},
+	}
+
+	converted := expectRoundTrip(t, in, v1alphaAPIVersion)
+
+	mas := &v1alpha.MydeviceAllocationState{}
+	if err := json.Unmarshal(converted, mas); err != nil {
+		t.Fatal(err)
+	}
+	if mas.Status != v1alphaStatusNotReady {
+		t.Errorf("expected status %v, got %v", v1alphaStatusNotReady, mas.Status)
+	}
+	if mas.Spec.AllocatableMydevices["dev0"].VendorID != "0x8086" {
+		t.Errorf("expected vendor ID in v1alpha field, got %+v", mas.Spec.AllocatableMydevices["dev0"])
+	}
+	if mas.Annotations["note"] != "kept" {
+		t.Errorf("expected own annotations to be kept, got %v", mas.Annotations)
+	}
+	data := v1beta1Data{}
+	if err := json.Unmarshal([]byte(mas.Annotations[v1beta1DataAnnotation]), &data); err != nil {
+		t.Fatalf("decoding %v annotation: %v", v1beta1DataAnnotation, err)
+	}
+	if expected := map[string]map[string]string{"dev0": {"firmware": "1.2.3", "memory": "16Gi"}}; !reflect.DeepEqual(data.Attributes, expected) {
+		t.Errorf("expected attributes %v in annotation, got %v", expected, data.Attributes)
+	}
+	if len(data.Conditions) != 2 {
+		t.Errorf("expected both conditions in annotation, got %v", data.Conditions)
+	}
+
+	// a v1alpha client changing the status wins over the stored Ready condition
+	mas.Status = v1alphaStatusReady
+	raw, err := json.Marshal(mas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	converted, err = Convert(raw, v1beta1APIVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &v1beta1.MydeviceAllocationState{}
+	if err := json.Unmarshal(converted, out); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := out.Annotations[v1beta1DataAnnotation]; exists {
+		t.Errorf("annotation %v not removed", v1beta1DataAnnotation)
+	}
+	if len(out.Status.Conditions) != 2 || out.Status.Conditions[0].Type != "HealthChecked" {
+		t.Fatalf("expected conditions in original order, got %+v", out.Status.Conditions)
+	}
+	if ready := out.Status.Conditions[1]; ready.Status != metav1.ConditionTrue || ready.Reason != v1alphaStatusReady {
+		t.Errorf("expected Ready condition from v1alpha status, got %+v", ready)
+	}
+
+	// a damaged annotation loses the v1beta1 only fields, not the object
+	mas.Annotations[v1beta1DataAnnotation] = "{"
+	raw, err = json.Marshal(mas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Convert(raw, v1beta1APIVersion); err != nil {
+		t.Errorf("damaged annotation failed the conversion: %v", err)
+	}
+}
+
+func TestMydeviceClaimParametersRoundTrip(t *testing.T) {
+	in := &v1alpha.MydeviceClaimParameters{
+		TypeMeta:   metav1.TypeMeta{Kind: "MydeviceClaimParameters", APIVersion: v1alphaAPIVersion},
+		ObjectMeta: testObjectMeta("params", "default"),
+		Spec:       v1alphaClaimParametersSpec(),
+	}
+	expectRoundTrip(t, in, v1beta1APIVersion)
+
+	exact := &v1alpha.MydeviceClaimParameters{
+		TypeMeta:   metav1.TypeMeta{Kind: "MydeviceClaimParameters", APIVersion: v1alphaAPIVersion},
+		ObjectMeta: testObjectMeta("exact", "default"),
+		Spec:       v1alpha.MydeviceClaimParametersSpec{Count: 1},
+	}
+	expectRoundTrip(t, exact, v1beta1APIVersion)
+
+	stored := &v1beta1.MydeviceClaimParameters{
+		TypeMeta:   metav1.TypeMeta{Kind: "MydeviceClaimParameters", APIVersion: v1beta1APIVersion},
+		ObjectMeta: testObjectMeta("stored", "default"),
+		Spec: v1beta1.MydeviceClaimParametersSpec{
+			Count:    2,
+			Type:     v1beta1.MydeviceType0,
+			Priority: int32Ptr(10),
+			Topology: &v1beta1.MydeviceTopologyConstraint{Scope: v1beta1.TopologyScopeNUMANode},
+		},
+	}
+	expectRoundTrip(t, stored, v1alphaAPIVersion)
+}
+
+func TestMydeviceClassParametersRoundTrip(t *testing.T) {
+	in := &v1alpha.MydeviceClassParameters{
+		TypeMeta:   metav1.TypeMeta{Kind: "MydeviceClassParameters", APIVersion: v1alphaAPIVersion},
+		ObjectMeta: testObjectMeta("class", ""),
+		Spec: v1alpha.MydeviceClassParametersSpec{
+			MydeviceSelector: []v1alpha.MydeviceSelector{
+				{Type: v1alpha.MydeviceType0, Name: "0000:03:*"},
+				{Type: "*", Name: "*-0x56a0"},
+			},
+			MaxSharers:       4,
+			AllocationPolicy: v1alpha.AllocationPolicySpread,
+		},
+	}
+	expectRoundTrip(t, in, v1beta1APIVersion)
+
+	empty := &v1alpha.MydeviceClassParameters{
+		TypeMeta:   metav1.TypeMeta{Kind: "MydeviceClassParameters", APIVersion: v1alphaAPIVersion},
+		ObjectMeta: testObjectMeta("empty", ""),
+	}
+	expectRoundTrip(t, empty, v1beta1APIVersion)
+}
+
+func TestMydeviceQuotaRoundTrip(t *testing.T) {
+	in := &v1alpha.MydeviceQuota{
+		TypeMeta:   metav1.TypeMeta{Kind: "MydeviceQuota", APIVersion: v1alphaAPIVersion},
+		ObjectMeta: testObjectMeta("quota", "default"),
+		Spec:       v1alpha.MydeviceQuotaSpec{Limits: map[v1alpha.MydeviceType]int{v1alpha.MydeviceType0: 4}},
+		Status:     v1alpha.MydeviceQuotaStatus{Used: map[v1alpha.MydeviceType]int{v1alpha.MydeviceType0: 2}},
+	}
+	expectRoundTrip(t, in, v1beta1APIVersion)
+
+	stored := &v1beta1.MydeviceQuota{
+		TypeMeta:   metav1.TypeMeta{Kind: "MydeviceQuota", APIVersion: v1beta1APIVersion},
+		ObjectMeta: testObjectMeta("stored", "default"),
+		Spec:       v1beta1.MydeviceQuotaSpec{Limits: map[v1beta1.MydeviceType]int{v1beta1.MydeviceType0: 8}},
+	}
+	expectRoundTrip(t, stored, v1alphaAPIVersion)
+}
+
+func TestConvertUnsupported(t *testing.T) {
+	testCases := []struct {
+		name    string
+		raw     string
+		version string
+	}{
+		{"unknown kind", `{"apiVersion":"dra.example.com/v1alpha","kind":"Mydevice"}`, v1beta1APIVersion},
+		{"unknown version", `{"apiVersion":"dra.example.com/v1","kind":"MydeviceQuota"}`, v1beta1APIVersion},
+		{"malformed object", `{"apiVersion":"dra.example.com/v1alpha","kind":"MydeviceQuota","spec":[]}`, v1beta1APIVersion},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Convert([]byte(tc.raw), tc.version); err == nil {
+				t.Errorf("expected conversion to fail")
+			}
+		})
+	}
+
+	raw := []byte(`{"apiVersion":"dra.example.com/v1beta1","kind":"Anything"}`)
+	out, err := Convert(raw, v1beta1APIVersion)
+	if err != nil || string(out) != string(raw) {
+		t.Errorf("expected object in desired version to be returned as is, got %s, %v", out, err)
+	}
+}
@@ -0,0 +1,323 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"encoding/json"
+	"sort"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1beta1"
+)
+
+// v1alpha status strings, kept here to not depend on the api wrapper package
+const (
+	v1alphaStatusReady    = "Ready"
+	v1alphaStatusNotReady = "NotReady"
+)
+
+// Annotation of v1alpha objects holding the v1beta1 fields that v1alpha has no
+// place for, so that they survive a round trip
+const v1beta1DataAnnotation = v1beta1.ApiGroupName + "/v1beta1-data"
+
+// v1beta1Data is the content of v1beta1DataAnnotation
+type v1beta1Data struct {
+	// device attributes other than the well known ones, by device UID
+	Attributes map[string]map[string]string `json:"attributes,omitempty"`
+	// all conditions, the Ready condition for its reason, message and times
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+var wellKnownAttributes = map[string]bool{
+	v1beta1.AttributeVendorID:   true,
+	v1beta1.AttributeDeviceID:   true,
+	v1beta1.AttributePCIAddress: true,
+}
+
+// MydeviceAllocationStateToV1beta1 turns the claim UID keyed maps into lists
+// sorted by key, PCI IDs into device attributes and the status into a Ready condition.
+// Fields kept in v1beta1DataAnnotation by MydeviceAllocationStateToV1alpha are restored.
+func MydeviceAllocationStateToV1beta1(in *v1alpha.MydeviceAllocationState) *v1beta1.MydeviceAllocationState {
+	out := &v1beta1.MydeviceAllocationState{
+		TypeMeta:   typeMeta(in.Kind, v1beta1APIVersion),
+		ObjectMeta: *in.ObjectMeta.DeepCopy(),
+	}
+
+	data := v1beta1Data{}
+	if value, exists := out.Annotations[v1beta1DataAnnotation]; exists {
+		// a damaged annotation only loses what v1alpha cannot hold anyway
+		_ = json.Unmarshal([]byte(value), &data)
+		delete(out.Annotations, v1beta1DataAnnotation)
+		if len(out.Annotations) == 0 {
+			out.Annotations = nil
+		}
+	}
+
+	for _, uid := range sortedKeys(in.Spec.AllocatableMydevices) {
+		device := in.Spec.AllocatableMydevices[uid]
+		outDevice := v1beta1.AllocatableMydevice{
+			UID:        uid,
+			Type:       v1beta1.MydeviceType(device.Type),
+			CDIDevice:  device.CDIDevice,
+			MaxSharers: device.MaxSharers,
+			Health:     v1beta1.MydeviceHealth(device.Health),
+			Cordoned:   device.Cordoned,
+			Attributes: attributes(map[string]string{
+				v1beta1.AttributeVendorID:   device.VendorID,
+				v1beta1.AttributeDeviceID:   device.DeviceID,
+				v1beta1.AttributePCIAddress: device.PCIAddress,
+			}),
+		}
+		for key, value := range data.Attributes[uid] {
+			if wellKnownAttributes[key] {
+				continue
+			}
+			if outDevice.Attributes == nil {
+				outDevice.Attributes = make(map[string]string)
+			}
+			outDevice.Attributes[key] = value
+		}
+		if device.Topology != nil {
+			topology := v1beta1.MydeviceTopology(*device.Topology)
+			outDevice.Topology = &topology
+		}
+		out.Spec.AllocatableDevices = append(out.Spec.AllocatableDevices, outDevice)
+	}
+
+	for _, claimUID := range sortedKeys(in.Spec.ResourceClaimAllocations) {
+		allocation := v1beta1.MydeviceClaimAllocation{
+			ClaimUID: claimUID,
+			Devices:  []v1beta1.AllocatedMydevice{},
+		}
+		for _, device := range in.Spec.ResourceClaimAllocations[claimUID] {
+			allocation.Devices = append(allocation.Devices, v1beta1.AllocatedMydevice{
+				UID:        device.UID,
+				Type:       v1beta1.MydeviceType(device.Type),
+				CDIDevice:  device.CDIDevice,
+				MaxSharers: device.MaxSharers,
+			})
+		}
+		out.Spec.Allocations = append(out.Spec.Allocations, allocation)
+	}
+
+	for _, claimUID := range sortedKeys(in.Spec.ResourceClaimRequests) {
+		request := in.Spec.ResourceClaimRequests[claimUID]
+		outRequest := v1beta1.MydeviceClaimRequest{
+			ClaimUID:       claimUID,
+			Spec:           claimParametersSpecToV1beta1(&request.Spec),
+			Devices:        []v1beta1.RequestedMydevice{},
+			AllocatedCount: request.AllocatedCount,
+			Siblings:       request.Siblings,
+		}
+		for _, device := range request.Mydevices {
+			outRequest.Devices = append(outRequest.Devices, v1beta1.RequestedMydevice(device))
+		}
+		out.Spec.Requests = append(out.Spec.Requests, outRequest)
+	}
+
+	ready := readyCondition(in)
+	for _, condition := range data.Conditions {
+		if condition.Type != v1beta1.MydeviceAllocationStateReady {
+			out.Status.Conditions = append(out.Status.Conditions, condition)
+			continue
+		}
+		// the v1alpha status wins if it changed since
+		if ready != nil && condition.Status == ready.Status {
+			ready = condition.DeepCopy()
+		}
+		if ready != nil {
+			out.Status.Conditions = append(out.Status.Conditions, *ready)
+			ready = nil
+		}
+	}
+	if ready != nil {
+		out.Status.Conditions = append(out.Status.Conditions, *ready)
+	}
+
+	return out
+}
+
+// MydeviceAllocationStateToV1alpha is the reverse of MydeviceAllocationStateToV1beta1.
+// Device attributes other than the well known ones and conditions the v1alpha
+// status string cannot express have no v1alpha counterpart and are kept in
+// v1beta1DataAnnotation.
+func MydeviceAllocationStateToV1alpha(in *v1beta1.MydeviceAllocationState) *v1alpha.MydeviceAllocationState {
+	out := &v1alpha.MydeviceAllocationState{
+		TypeMeta:   typeMeta(in.Kind, v1alphaAPIVersion),
+		ObjectMeta: *in.ObjectMeta.DeepCopy(),
+	}
+
+	data := v1beta1Data{}
+
+	if in.Spec.AllocatableDevices != nil {
+		out.Spec.AllocatableMydevices = make(map[string]v1alpha.AllocatableMydevice)
+	}
+	for _, device := range in.Spec.AllocatableDevices {
+		outDevice := v1alpha.AllocatableMydevice{
+			UID:        device.UID,
+			Type:       v1alpha.MydeviceType(device.Type),
+			CDIDevice:  device.CDIDevice,
+			MaxSharers: device.MaxSharers,
+			Health:     v1alpha.MydeviceHealth(device.Health),
+			Cordoned:   device.Cordoned,
+			VendorID:   device.Attributes[v1beta1.AttributeVendorID],
+			DeviceID:   device.Attributes[v1beta1.AttributeDeviceID],
+			PCIAddress: device.Attributes[v1beta1.AttributePCIAddress],
+		}
+		if device.Topology != nil {
+			topology := v1alpha.MydeviceTopology(*device.Topology)
+			outDevice.Topology = &topology
+		}
+		out.Spec.AllocatableMydevices[device.UID] = outDevice
+
+		for key, value := range device.Attributes {
+			if wellKnownAttributes[key] {
+				continue
+			}
+			if data.Attributes == nil {
+				data.Attributes = make(map[string]map[string]string)
+			}
+			if data.Attributes[device.UID] == nil {
+				data.Attributes[device.UID] = make(map[string]string)
+			}
+			data.Attributes[device.UID][key] = value
+		}
+	}
+
+	if in.Spec.Allocations != nil {
+		out.Spec.ResourceClaimAllocations = make(map[string]v1alpha.AllocatedMydevices)
+	}
+	for _, allocation := range in.Spec.Allocations {
+		devices := v1alpha.AllocatedMydevices{}
+		for _, device := range allocation.Devices {
+			devices = append(devices, v1alpha.AllocatedMydevice{
+				UID:        device.UID,
+				Type:       v1alpha.MydeviceType(device.Type),
+				CDIDevice:  device.CDIDevice,
+				MaxSharers: device.MaxSharers,
+			})
+		}
+		out.Spec.ResourceClaimAllocations[allocation.ClaimUID] = devices
+	}
+
+	if in.Spec.Requests != nil {
+		out.Spec.ResourceClaimRequests = make(map[string]v1alpha.RequestedMydevices)
+	}
+	for _, request := range in.Spec.Requests {
+		outRequest := v1alpha.RequestedMydevices{
+			Spec:           claimParametersSpecToV1alpha(&request.Spec),
+			Mydevices:      []v1alpha.RequestedMydevice{},
+			AllocatedCount: request.AllocatedCount,
+			Siblings:       request.Siblings,
+		}
+		for _, device := range request.Devices {
+			outRequest.Mydevices = append(outRequest.Mydevices, v1alpha.RequestedMydevice(device))
+		}
+		out.Spec.ResourceClaimRequests[request.ClaimUID] = outRequest
+	}
+
+	for _, condition := range in.Status.Conditions {
+		if condition.Type != v1beta1.MydeviceAllocationStateReady {
+			continue
+		}
+		switch condition.Status {
+		case metav1.ConditionTrue:
+			out.Status = v1alphaStatusReady
+		case metav1.ConditionFalse:
+			out.Status = v1alphaStatusNotReady
+		default:
+			// readyCondition keeps unknown status strings in the message
+			out.Status = condition.Message
+		}
+	}
+
+	// a lone Ready condition that the v1alpha status converts back to is not worth keeping
+	data.Conditions = in.Status.Conditions
+	if ready := readyCondition(out); ready != nil && len(data.Conditions) == 1 && apiequality.Semantic.DeepEqual(data.Conditions[0], *ready) {
+		data.Conditions = nil
+	}
+
+	if data.Attributes != nil || data.Conditions != nil {
+		// marshalling plain maps and conditions does not fail
+		value, _ := json.Marshal(data)
+		if out.Annotations == nil {
+			out.Annotations = make(map[string]string)
+		}
+		out.Annotations[v1beta1DataAnnotation] = string(value)
+	}
+
+	return out
+}
+
+// readyCondition maps the v1alpha status string to a Ready condition, nil if no status is set.
+// v1alpha does not track transitions, the object creation time stands in for it. Objects
+// not created yet get the Unix epoch, converting the same object twice gives the same result.
+func readyCondition(in *v1alpha.MydeviceAllocationState) *metav1.Condition {
+	if in.Status == "" {
+		return nil
+	}
+
+	condition := &metav1.Condition{
+		Type:               v1beta1.MydeviceAllocationStateReady,
+		Status:             metav1.ConditionUnknown,
+		Reason:             "Unknown",
+		Message:            in.Status,
+		ObservedGeneration: in.Generation,
+		LastTransitionTime: in.CreationTimestamp,
+	}
+	switch in.Status {
+	case v1alphaStatusReady:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = v1alphaStatusReady
+		condition.Message = ""
+	case v1alphaStatusNotReady:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = v1alphaStatusNotReady
+		condition.Message = ""
+	}
+	if condition.LastTransitionTime.IsZero() {
+		condition.LastTransitionTime = metav1.Unix(0, 0)
+	}
+	return condition
+}
+
+// attributes drops empty values, nil if none is left
+func attributes(in map[string]string) map[string]string {
+	var out map[string]string
+	for key, value := range in {
+		if value == "" {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string)
+		}
+		out[key] = value
+	}
+	return out
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1beta1"
+)
+
+func MydeviceClaimParametersToV1beta1(in *v1alpha.MydeviceClaimParameters) *v1beta1.MydeviceClaimParameters {
+	return &v1beta1.MydeviceClaimParameters{
+		TypeMeta:   typeMeta(in.Kind, v1beta1APIVersion),
+		ObjectMeta: *in.ObjectMeta.DeepCopy(),
+		Spec:       claimParametersSpecToV1beta1(&in.Spec),
+	}
+}
+
+func MydeviceClaimParametersToV1alpha(in *v1beta1.MydeviceClaimParameters) *v1alpha.MydeviceClaimParameters {
+	return &v1alpha.MydeviceClaimParameters{
+		TypeMeta:   typeMeta(in.Kind, v1alphaAPIVersion),
+		ObjectMeta: *in.ObjectMeta.DeepCopy(),
+		Spec:       claimParametersSpecToV1alpha(&in.Spec),
+	}
+}
+
+func claimParametersSpecToV1beta1(in *v1alpha.MydeviceClaimParametersSpec) v1beta1.MydeviceClaimParametersSpec {
+	in = in.DeepCopy()
+	out := v1beta1.MydeviceClaimParametersSpec{
+		Count:    in.Count,
+		MinCount: in.MinCount,
+		MaxCount: in.MaxCount,
+		Type:     v1beta1.MydeviceType(in.Type),
+		Priority: in.Priority,
+	}
+	if in.Selector != nil {
+		selector := v1beta1.MydeviceClaimSelector(*in.Selector)
+		out.Selector = &selector
+	}
+	if in.Topology != nil {
+		out.Topology = &v1beta1.MydeviceTopologyConstraint{
+			Scope:  v1beta1.TopologyScope(in.Topology.Scope),
+			Policy: v1beta1.TopologyPolicy(in.Topology.Policy),
+		}
+	}
+	return out
+}
+
+func claimParametersSpecToV1alpha(in *v1beta1.MydeviceClaimParametersSpec) v1alpha.MydeviceClaimParametersSpec {
+	in = in.DeepCopy()
+	out := v1alpha.MydeviceClaimParametersSpec{
+		Count:    in.Count,
+		MinCount: in.MinCount,
+		MaxCount: in.MaxCount,
+		Type:     v1alpha.MydeviceType(in.Type),
+		Priority: in.Priority,
+	}
+	if in.Selector != nil {
+		selector := v1alpha.MydeviceClaimSelector(*in.Selector)
+		out.Selector = &selector
+	}
+	if in.Topology != nil {
+		out.Topology = &v1alpha.MydeviceTopologyConstraint{
+			Scope:  v1alpha.TopologyScope(in.Topology.Scope),
+			Policy: v1alpha.TopologyPolicy(in.Topology.Policy),
+		}
+	}
+	return out
+}
+
+func MydeviceClassParametersToV1beta1(in *v1alpha.MydeviceClassParameters) *v1beta1.MydeviceClassParameters {
+	out := &v1beta1.MydeviceClassParameters{
+		TypeMeta:   typeMeta(in.Kind, v1beta1APIVersion),
+		ObjectMeta: *in.ObjectMeta.DeepCopy(),
+		Spec: v1beta1.MydeviceClassParametersSpec{
+			MaxSharers:       in.Spec.MaxSharers,
+			AllocationPolicy: v1beta1.AllocationPolicy(in.Spec.AllocationPolicy),
+		},
+	}
+	for _, selector := range in.Spec.MydeviceSelector {
+		out.Spec.Selectors = append(out.Spec.Selectors, v1beta1.MydeviceSelector(selector))
+	}
+	return out
+}
+
+func MydeviceClassParametersToV1alpha(in *v1beta1.MydeviceClassParameters) *v1alpha.MydeviceClassParameters {
+	out := &v1alpha.MydeviceClassParameters{
+		TypeMeta:   typeMeta(in.Kind, v1alphaAPIVersion),
+		ObjectMeta: *in.ObjectMeta.DeepCopy(),
+		Spec: v1alpha.MydeviceClassParametersSpec{
+			MaxSharers:       in.Spec.MaxSharers,
+			AllocationPolicy: v1alpha.AllocationPolicy(in.Spec.AllocationPolicy),
+		},
+	}
+	for _, selector := range in.Spec.Selectors {
+		out.Spec.MydeviceSelector = append(out.Spec.MydeviceSelector, v1alpha.MydeviceSelector(selector))
+	}
+	return out
+}
+
+func MydeviceQuotaToV1beta1(in *v1alpha.MydeviceQuota) *v1beta1.MydeviceQuota {
+	out := &v1beta1.MydeviceQuota{
+		TypeMeta:   typeMeta(in.Kind, v1beta1APIVersion),
+		ObjectMeta: *in.ObjectMeta.DeepCopy(),
+	}
+	if in.Spec.Limits != nil {
+		out.Spec.Limits = make(map[v1beta1.MydeviceType]int)
+		for deviceType, limit := range in.Spec.Limits {
+			out.Spec.Limits[v1beta1.MydeviceType(deviceType)] = limit
+		}
+	}
+	if in.Status.Used != nil {
+		out.Status.Used = make(map[v1beta1.MydeviceType]int)
+		for deviceType, used := range in.Status.Used {
+			out.Status.Used[v1beta1.MydeviceType(deviceType)] = used
+		}
+	}
+	return out
+}
+
+func MydeviceQuotaToV1alpha(in *v1beta1.MydeviceQuota) *v1alpha.MydeviceQuota {
+	out := &v1alpha.MydeviceQuota{
+		TypeMeta:   typeMeta(in.Kind, v1alphaAPIVersion),
+		ObjectMeta: *in.ObjectMeta.DeepCopy(),
+	}
+	if in.Spec.Limits != nil {
+		out.Spec.Limits = make(map[v1alpha.MydeviceType]int)
+		for deviceType, limit := range in.Spec.Limits {
+			out.Spec.Limits[v1alpha.MydeviceType(deviceType)] = limit
+		}
+	}
+	if in.Status.Used != nil {
+		out.Status.Used = make(map[v1alpha.MydeviceType]int)
+		for deviceType, used := range in.Status.Used {
+			out.Status.Used[v1alpha.MydeviceType(deviceType)] = used
+		}
+	}
+	return out
+}
@@ -30,6 +30,8 @@ type Interface interface {
 	MydeviceClaimParameters() MydeviceClaimParametersInformer
 	// MydeviceClassParameters returns a MydeviceClassParametersInformer.
 	MydeviceClassParameters() MydeviceClassParametersInformer
+	// MydeviceQuotas returns a MydeviceQuotaInformer.
+	MydeviceQuotas() MydeviceQuotaInformer
 }
 
 type version struct {
@@ -57,3 +59,8 @@ func (v *version) MydeviceClaimParameters() MydeviceClaimParametersInformer {
 func (v *version) MydeviceClassParameters() MydeviceClassParametersInformer {
 	return &mydeviceClassParametersInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
+
+// MydeviceQuotas returns a MydeviceQuotaInformer.
+func (v *version) MydeviceQuotas() MydeviceQuotaInformer {
+	return &mydeviceQuotaInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
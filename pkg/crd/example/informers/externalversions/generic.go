@@ -22,6 +22,7 @@ import (
 	"fmt"
 
 	v1alpha "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+	v1beta1 "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1beta1"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 )
@@ -59,6 +60,18 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Dra().V1alpha().MydeviceClaimParameters().Informer()}, nil
 	case v1alpha.SchemeGroupVersion.WithResource("mydeviceclassparameters"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Dra().V1alpha().MydeviceClassParameters().Informer()}, nil
+	case v1alpha.SchemeGroupVersion.WithResource("mydevicequotas"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Dra().V1alpha().MydeviceQuotas().Informer()}, nil
+
+		// Group=dra.example.com, Version=v1beta1
+	case v1beta1.SchemeGroupVersion.WithResource("mydeviceallocationstates"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Dra().V1beta1().MydeviceAllocationStates().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("mydeviceclaimparameters"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Dra().V1beta1().MydeviceClaimParameters().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("mydeviceclassparameters"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Dra().V1beta1().MydeviceClassParameters().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("mydevicequotas"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Dra().V1beta1().MydeviceQuotas().Informer()}, nil
 
 	}
 
@@ -37,3 +37,11 @@ type MydeviceClaimParametersNamespaceListerExpansion interface{}
 // MydeviceClassParametersListerExpansion allows custom methods to be added to
 // MydeviceClassParametersLister.
 type MydeviceClassParametersListerExpansion interface{}
+
+// MydeviceQuotaListerExpansion allows custom methods to be added to
+// MydeviceQuotaLister.
+type MydeviceQuotaListerExpansion interface{}
+
+// MydeviceQuotaNamespaceListerExpansion allows custom methods to be added to
+// MydeviceQuotaNamespaceLister.
+type MydeviceQuotaNamespaceListerExpansion interface{}
@@ -18,6 +18,7 @@ package api
 
 import (
 	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+	"github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1beta1"
 )
 
 const (
@@ -25,5 +26,28 @@ const (
 	ApiVersion                  = mycrd.ApiVersion
 	MydeviceType0               = mycrd.MydeviceType0
 	UnknownDeviceType           = mycrd.UnknownDeviceType
+	AllocationPolicyPack        = mycrd.AllocationPolicyPack
+	AllocationPolicySpread      = mycrd.AllocationPolicySpread
+	TopologyScopeNUMANode       = mycrd.TopologyScopeNUMANode
+	TopologyScopePCIRoot        = mycrd.TopologyScopePCIRoot
+	TopologyScopePCISwitch      = mycrd.TopologyScopePCISwitch
+	TopologyPolicyPrefer        = mycrd.TopologyPolicyPrefer
+	TopologyPolicyRequire       = mycrd.TopologyPolicyRequire
 	MydeviceClaimParametersKind = "MydeviceClaimParameters"
 )
+
+// ParametersAPIGroups are the API group and version strings that resource classes
+// and claims can reference parameters with, all versions are served through conversion
+var ParametersAPIGroups = []string{
+	ApiGroupName + "/" + ApiVersion,
+	ApiGroupName + "/" + v1beta1.ApiVersion,
+}
+
+func IsParametersAPIGroup(apiGroup string) bool {
+	for _, group := range ParametersAPIGroups {
+		if apiGroup == group {
+			return true
+		}
+	}
+	return false
+}
@@ -17,21 +17,74 @@ limitations under the License.
 package api
 
 import (
+	"fmt"
+	"path"
+
 	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
 )
 
+const (
+	// MydeviceSelectorWildcard matches any device type or name
+	MydeviceSelectorWildcard = "*"
+)
+
 type MydeviceSelector = mycrd.MydeviceSelector
 type MydeviceClassParametersSpec = mycrd.MydeviceClassParametersSpec
 type MydeviceClassParameters = mycrd.MydeviceClassParameters
 type MydeviceClassParametersList = mycrd.MydeviceClassParametersList
+type AllocationPolicy = mycrd.AllocationPolicy
 
 func DefaultDeviceClassParametersSpec() *MydeviceClassParametersSpec {
 	return &MydeviceClassParametersSpec{
 		MydeviceSelector: []MydeviceSelector{
 			{
 				Type: mycrd.MydeviceType0,
-				Name: "*",
+				Name: MydeviceSelectorWildcard,
 			},
 		},
 	}
 }
+
+// ValidateMydeviceSelectors checks that selector name patterns are well-formed
+func ValidateMydeviceSelectors(selectors []MydeviceSelector) error {
+	for idx, selector := range selectors {
+		if _, err := path.Match(selector.Name, ""); err != nil {
+			return fmt.Errorf("selector %d: malformed name pattern '%v': %v", idx, selector.Name, err)
+		}
+	}
+	return nil
+}
+
+// DeviceMatchesSelectors returns true if device matches at least one of the selectors.
+// Empty selector list does not restrict devices.
+func DeviceMatchesSelectors(device *AllocatableMydevice, selectors []MydeviceSelector) bool {
+	if len(selectors) == 0 {
+		return true
+	}
+
+	for _, selector := range selectors {
+		if deviceMatchesSelector(device, &selector) {
+			return true
+		}
+	}
+	return false
+}
+
+// Type must be equal unless empty or wildcard, Name is a shell pattern
+// matched against device UID or CDI device name.
+func deviceMatchesSelector(device *AllocatableMydevice, selector *MydeviceSelector) bool {
+	if selector.Type != "" && selector.Type != MydeviceSelectorWildcard && selector.Type != string(device.Type) {
+		return false
+	}
+
+	if selector.Name == "" || selector.Name == MydeviceSelectorWildcard {
+		return true
+	}
+
+	for _, name := range []string{device.UID, device.CDIDevice} {
+		if matched, err := path.Match(selector.Name, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
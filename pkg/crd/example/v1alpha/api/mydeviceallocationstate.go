@@ -18,6 +18,7 @@ package api
 
 import (
 	"context"
+	"math"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -32,13 +33,21 @@ const (
 	MydeviceAllocationStateStatusNotReady = "NotReady"
 )
 
+const (
+	MydeviceHealthy   = mycrd.MydeviceHealthy
+	MydeviceUnhealthy = mycrd.MydeviceUnhealthy
+)
+
 type MydeviceAllocationStateConfig struct {
 	Name      string
 	Namespace string
 	Owner     *metav1.OwnerReference
 }
 
+type MydeviceType = mycrd.MydeviceType
 type AllocatableMydevice = mycrd.AllocatableMydevice
+type MydeviceTopology = mycrd.MydeviceTopology
+type MydeviceHealth = mycrd.MydeviceHealth
 type AllocatedMydevice = mycrd.AllocatedMydevice
 type AllocatedMydevices = mycrd.AllocatedMydevices
 type RequestedMydevice = mycrd.RequestedMydevice
@@ -71,6 +80,15 @@ func NewMydeviceAllocationState(config *MydeviceAllocationStateConfig, clientset
 	return mas
 }
 
+// NewMydeviceAllocationStateFromObject wraps a copy of an already fetched object,
+// for instance one from an informer cache, so that it can be modified and updated
+func NewMydeviceAllocationStateFromObject(object *mycrd.MydeviceAllocationState, clientset myclientset.Interface) *MydeviceAllocationState {
+	return &MydeviceAllocationState{
+		object.DeepCopy(),
+		clientset,
+	}
+}
+
 func (g *MydeviceAllocationState) GetOrCreate() error {
 	err := g.Get()
 	if err == nil {
@@ -148,20 +166,30 @@ func (g *MydeviceAllocationState) ListNames() ([]string, error) {
 	return masnames, nil
 }
 
-// Return list of Allocatable devices that are not yet allocated and are available
+// Return list of Allocatable devices that are not yet allocated, or are
+// shared and can take at least one more sharing claim
 func (g *MydeviceAllocationState) Available() map[string]*mycrd.AllocatableMydevice {
 	available := make(map[string]*mycrd.AllocatableMydevice)
 
 	klog.V(5).Infof("MAS spec has %v allocatable devices, %v claimallocations", len(g.Spec.AllocatableMydevices), len(g.Spec.ResourceClaimAllocations))
 
+	consumers := g.Consumers()
 	for _, device := range g.Spec.AllocatableMydevices {
 		switch device.Type {
 		case mycrd.MydeviceType0:
-			// TODO: remove this check in case mydevice is freely shareable
-			if g.DeviceIsAllocated(device.UID) {
+			if device.Health == MydeviceUnhealthy {
+				klog.V(5).Infof("Skipping unhealthy device %v", device.UID)
+				continue
+			}
+			if device.Cordoned {
+				klog.V(5).Infof("Skipping cordoned device %v", device.UID)
+				continue
+			}
+			if !consumers.CanAdd(device.UID, EffectiveMaxSharers(&device, math.MaxInt32)) {
 				continue
 			}
 
+			device := device
 			available[device.UID] = &device
 		default:
 			klog.Warning("Unsupported device type: %v", string(device.Type))
@@ -173,14 +201,75 @@ func (g *MydeviceAllocationState) Available() map[string]*mycrd.AllocatableMydev
 }
 
 func (g *MydeviceAllocationState) DeviceIsAllocated(deviceUid string) bool {
+	return g.DeviceConsumerCount(deviceUid) > 0
+}
+
+// DeviceConsumerCount returns number of claims the device is allocated to
+func (g *MydeviceAllocationState) DeviceConsumerCount(deviceUid string) int {
+	count := 0
 	for _, claimAllocation := range g.Spec.ResourceClaimAllocations {
 		for _, allocatedDevice := range claimAllocation {
 			if allocatedDevice.UID == deviceUid {
-				return true
+				count++
 			}
 		}
 	}
-	return false
+	return count
+}
+
+// DeviceConsumers maps device UID to sharer limits of the claims holding the device
+type DeviceConsumers map[string][]int
+
+// Consumers returns sharer limits of all claim allocations per device
+func (g *MydeviceAllocationState) Consumers() DeviceConsumers {
+	consumers := make(DeviceConsumers)
+	for _, claimAllocation := range g.Spec.ResourceClaimAllocations {
+		for _, allocatedDevice := range claimAllocation {
+			consumers.Add(allocatedDevice.UID, allocatedDevice.MaxSharers)
+		}
+	}
+	return consumers
+}
+
+// CanAdd returns true if one more claim with given sharer limit fits the device.
+// The strictest limit among current holders and the new claim applies.
+func (c DeviceConsumers) CanAdd(deviceUid string, maxSharers int) bool {
+	holders := c[deviceUid]
+	limit := sharerLimit(maxSharers)
+	for _, holderLimit := range holders {
+		if holderLimit < limit {
+			limit = holderLimit
+		}
+	}
+	return len(holders) < limit
+}
+
+func (c DeviceConsumers) Add(deviceUid string, maxSharers int) {
+	c[deviceUid] = append(c[deviceUid], sharerLimit(maxSharers))
+}
+
+// EffectiveMaxSharers combines device and resource class sharer limits, 0 means not set.
+// The lower limit wins if both are set, so a class cannot share a device the node declared
+// exclusive. Devices without limit, the kubelet plugin default, are shared as the class says.
+func EffectiveMaxSharers(device *AllocatableMydevice, classMaxSharers int) int {
+	switch {
+	case device.MaxSharers > 0 && classMaxSharers > 0:
+		if device.MaxSharers < classMaxSharers {
+			return device.MaxSharers
+		}
+		return classMaxSharers
+	case device.MaxSharers > 0:
+		return device.MaxSharers
+	default:
+		return sharerLimit(classMaxSharers)
+	}
+}
+
+func sharerLimit(maxSharers int) int {
+	if maxSharers < 1 {
+		return 1
+	}
+	return maxSharers
 }
 
 func (g *MydeviceAllocationState) MakeResourceClaimAllocation(claimUID string) {
@@ -189,9 +278,10 @@ func (g *MydeviceAllocationState) MakeResourceClaimAllocation(claimUID string) {
 		sourceDevice, _ := g.Spec.AllocatableMydevices[device.UID]
 		// TODO: check if sourceDevice is found
 		allocated = append(allocated, mycrd.AllocatedMydevice{
-			CDIDevice: sourceDevice.CDIDevice,
-			Type:      sourceDevice.Type,
-			UID:       sourceDevice.UID,
+			CDIDevice:  sourceDevice.CDIDevice,
+			Type:       sourceDevice.Type,
+			UID:        sourceDevice.UID,
+			MaxSharers: device.MaxSharers,
 		})
 	}
 	if g.Spec.ResourceClaimAllocations == nil {
@@ -0,0 +1,116 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"sort"
+	"testing"
+
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+)
+
+func TestEffectiveMaxSharers(t *testing.T) {
+	testCases := []struct {
+		name          string
+		deviceLimit   int
+		classLimit    int
+		expectedLimit int
+	}{
+		{"neither set", 0, 0, 1},
+		{"class only", 0, 3, 3},
+		{"device only", 2, 0, 2},
+		{"device lower", 2, 4, 2},
+		{"class lower", 4, 2, 2},
+		{"device exclusive", 1, 4, 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			device := &AllocatableMydevice{UID: "dev0", MaxSharers: tc.deviceLimit}
+			if limit := EffectiveMaxSharers(device, tc.classLimit); limit != tc.expectedLimit {
+				t.Errorf("expected %d, got %d", tc.expectedLimit, limit)
+			}
+		})
+	}
+}
+
+func TestDeviceConsumersCanAdd(t *testing.T) {
+	testCases := []struct {
+		name       string
+		holders    []int
+		maxSharers int
+		expected   bool
+	}{
+		{"free exclusive", nil, 1, true},
+		{"free without limit", nil, 0, true},
+		{"held exclusive", []int{1}, 1, false},
+		{"held, new claim shares", []int{1}, 2, false},
+		{"shared, room left", []int{3}, 3, true},
+		{"shared, full", []int{2, 2}, 2, false},
+		{"shared, new claim exclusive", []int{3}, 1, false},
+		{"strictest holder wins", []int{4, 2}, 4, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			consumers := DeviceConsumers{}
+			for _, limit := range tc.holders {
+				consumers.Add("dev0", limit)
+			}
+			if canAdd := consumers.CanAdd("dev0", tc.maxSharers); canAdd != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, canAdd)
+			}
+		})
+	}
+}
+
+func TestAvailable(t *testing.T) {
+	mas := NewMydeviceAllocationStateFromObject(&mycrd.MydeviceAllocationState{
+		Spec: mycrd.MydeviceAllocationStateSpec{
+			AllocatableMydevices: map[string]mycrd.AllocatableMydevice{
+				"free":      {UID: "free", Type: MydeviceType0},
+				"exclusive": {UID: "exclusive", Type: MydeviceType0},
+				"shared":    {UID: "shared", Type: MydeviceType0, MaxSharers: 3},
+				"full":      {UID: "full", Type: MydeviceType0, MaxSharers: 2},
+				"unhealthy": {UID: "unhealthy", Type: MydeviceType0, Health: MydeviceUnhealthy},
+				"cordoned":  {UID: "cordoned", Type: MydeviceType0, Cordoned: true},
+				// the class decides, the claim holding it allows one more
+				"classShared": {UID: "classShared", Type: MydeviceType0},
+			},
+			ResourceClaimAllocations: map[string]mycrd.AllocatedMydevices{
+				"claim-a": {{UID: "exclusive", MaxSharers: 1}, {UID: "shared", MaxSharers: 3}, {UID: "full", MaxSharers: 2}},
+				"claim-b": {{UID: "full", MaxSharers: 2}, {UID: "classShared", MaxSharers: 2}},
+			},
+		},
+	}, nil)
+
+	available := []string{}
+	for uid := range mas.Available() {
+		available = append(available, uid)
+	}
+	sort.Strings(available)
+
+	expected := []string{"classShared", "free", "shared"}
+	if len(available) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, available)
+	}
+	for i := range expected {
+		if available[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, available)
+		}
+	}
+}
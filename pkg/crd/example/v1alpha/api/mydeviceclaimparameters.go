@@ -17,12 +17,18 @@ limitations under the License.
 package api
 
 import (
+	"strings"
+
 	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
 )
 
 type MydeviceClaimParametersSpec = mycrd.MydeviceClaimParametersSpec
 type MydeviceClaimParameters = mycrd.MydeviceClaimParameters
 type MydeviceClaimParametersList = mycrd.MydeviceClaimParametersList
+type MydeviceClaimSelector = mycrd.MydeviceClaimSelector
+type MydeviceTopologyConstraint = mycrd.MydeviceTopologyConstraint
+type TopologyScope = mycrd.TopologyScope
+type TopologyPolicy = mycrd.TopologyPolicy
 
 func DefaultMydeviceClaimParametersSpec() *MydeviceClaimParametersSpec {
 	return &MydeviceClaimParametersSpec{
@@ -30,3 +36,52 @@ func DefaultMydeviceClaimParametersSpec() *MydeviceClaimParametersSpec {
 		Type:  mycrd.MydeviceType0,
 	}
 }
+
+// CountRange returns the fewest and most devices the claim takes. Without
+// MaxCount the claim takes exactly Count devices.
+func CountRange(spec *MydeviceClaimParametersSpec) (int, int) {
+	if spec.MaxCount == 0 {
+		return spec.Count, spec.Count
+	}
+
+	minCount := spec.MinCount
+	if minCount == 0 {
+		minCount = 1
+	}
+	return minCount, spec.MaxCount
+}
+
+// CountSatisfied returns true if the number of devices is within the count range of the claim
+func CountSatisfied(spec *MydeviceClaimParametersSpec, count int) bool {
+	minCount, maxCount := CountRange(spec)
+	return count >= minCount && count <= maxCount
+}
+
+// DeviceMatchesClaimSelector returns true if all attributes set in the selector
+// match the device. Nil selector matches any device.
+func DeviceMatchesClaimSelector(device *AllocatableMydevice, selector *MydeviceClaimSelector) bool {
+	if selector == nil {
+		return true
+	}
+
+	if selector.VendorID != "" && normalizePCIID(selector.VendorID) != normalizePCIID(device.VendorID) {
+		return false
+	}
+
+	if selector.DeviceID != "" && normalizePCIID(selector.DeviceID) != normalizePCIID(device.DeviceID) {
+		return false
+	}
+
+	if selector.PCIAddressPrefix != "" &&
+		!strings.HasPrefix(strings.ToLower(device.PCIAddress), strings.ToLower(selector.PCIAddressPrefix)) {
+		return false
+	}
+
+	return true
+}
+
+// sysfs reports IDs as 0x8086, users often write 8086 or 0X8086
+func normalizePCIID(id string) string {
+	id = strings.ToLower(strings.TrimSpace(id))
+	return strings.TrimPrefix(id, "0x")
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"sort"
+
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+)
+
+type MydeviceQuotaSpec = mycrd.MydeviceQuotaSpec
+type MydeviceQuotaStatus = mycrd.MydeviceQuotaStatus
+type MydeviceQuota = mycrd.MydeviceQuota
+type MydeviceQuotaList = mycrd.MydeviceQuotaList
+
+// MydeviceUsage counts devices per type
+type MydeviceUsage map[MydeviceType]int
+
+// Add adds the devices of other to the usage
+func (u MydeviceUsage) Add(other MydeviceUsage) {
+	for deviceType, count := range other {
+		u[deviceType] += count
+	}
+}
+
+// Equal returns true if both count the same devices, missing types count as zero
+func (u MydeviceUsage) Equal(other map[MydeviceType]int) bool {
+	for deviceType, count := range u {
+		if other[deviceType] != count {
+			return false
+		}
+	}
+	for deviceType, count := range other {
+		if u[deviceType] != count {
+			return false
+		}
+	}
+	return true
+}
+
+// QuotaExceeded describes every limit of the quota that used and requested
+// devices together would exceed. Empty result means the request fits.
+func QuotaExceeded(quota *MydeviceQuota, used MydeviceUsage, requested MydeviceUsage) []string {
+	exceeded := []string{}
+	for deviceType, count := range requested {
+		limit, limited := quota.Spec.Limits[deviceType]
+		if !limited || count == 0 {
+			continue
+		}
+		if used[deviceType]+count > limit {
+			exceeded = append(exceeded, fmt.Sprintf("MydeviceQuota %v: %v %v devices requested, %v of %v used",
+				quota.Name, count, deviceType, used[deviceType], limit))
+		}
+	}
+	sort.Strings(exceeded)
+	return exceeded
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	ResourceHandleVersion = "v1"
+)
+
+// ResourceHandle is passed by the controller to the kubelet plugin through
+// AllocationResult.ResourceHandle, it lists devices allocated for the claim
+type ResourceHandle struct {
+	Version   string             `json:"version"`
+	Mydevices AllocatedMydevices `json:"mydevices"`
+}
+
+func EncodeResourceHandle(devices AllocatedMydevices) (string, error) {
+	handle := ResourceHandle{
+		Version:   ResourceHandleVersion,
+		Mydevices: devices,
+	}
+
+	data, err := json.Marshal(handle)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode resource handle: %v", err)
+	}
+	return string(data), nil
+}
+
+func DecodeResourceHandle(data string) (*ResourceHandle, error) {
+	handle := &ResourceHandle{}
+	if err := json.Unmarshal([]byte(data), handle); err != nil {
+		return nil, fmt.Errorf("failed to decode resource handle: %v", err)
+	}
+
+	if handle.Version != ResourceHandleVersion {
+		return nil, fmt.Errorf("unsupported resource handle version '%v', expected '%v'", handle.Version, ResourceHandleVersion)
+	}
+	return handle, nil
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"regexp"
+
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+)
+
+// Limits on claimed device count, same as in the CRD schema
+const (
+	MinClaimCount = 1
+	MaxClaimCount = 8
+)
+
+var (
+	pciIDRE            = regexp.MustCompile(`^(0[xX])?[0-9a-fA-F]{1,4}$`)
+	pciAddressPrefixRE = regexp.MustCompile(`^[0-9a-fA-F:.]*$`)
+)
+
+// IsKnownMydeviceType returns true for device types this driver can allocate
+func IsKnownMydeviceType(deviceType MydeviceType) bool {
+	switch deviceType {
+	case mycrd.MydeviceType0:
+		return true
+	}
+	return false
+}
+
+// ValidateMydeviceClaimParametersSpec checks claim parameters, including what
+// the CRD schema cannot express
+func ValidateMydeviceClaimParametersSpec(spec *MydeviceClaimParametersSpec) error {
+	if spec.MaxCount == 0 {
+		if spec.MinCount != 0 {
+			return fmt.Errorf("minCount requires maxCount")
+		}
+		if spec.Count < MinClaimCount || spec.Count > MaxClaimCount {
+			return fmt.Errorf("count %v out of range %v-%v", spec.Count, MinClaimCount, MaxClaimCount)
+		}
+	} else {
+		if spec.Count != 0 {
+			return fmt.Errorf("count and maxCount are mutually exclusive")
+		}
+		minCount, maxCount := CountRange(spec)
+		if minCount < MinClaimCount || maxCount > MaxClaimCount || minCount > maxCount {
+			return fmt.Errorf("count range %v-%v out of range %v-%v", minCount, maxCount, MinClaimCount, MaxClaimCount)
+		}
+	}
+
+	if spec.Type != "" && !IsKnownMydeviceType(spec.Type) {
+		return fmt.Errorf("unknown device type '%v'", spec.Type)
+	}
+
+	if selector := spec.Selector; selector != nil {
+		if selector.VendorID != "" && !pciIDRE.MatchString(selector.VendorID) {
+			return fmt.Errorf("selector: malformed vendorID '%v', expected up to 4 hex digits", selector.VendorID)
+		}
+		if selector.DeviceID != "" && !pciIDRE.MatchString(selector.DeviceID) {
+			return fmt.Errorf("selector: malformed deviceID '%v', expected up to 4 hex digits", selector.DeviceID)
+		}
+		if !pciAddressPrefixRE.MatchString(selector.PCIAddressPrefix) {
+			return fmt.Errorf("selector: malformed pciAddressPrefix '%v'", selector.PCIAddressPrefix)
+		}
+	}
+
+	if topology := spec.Topology; topology != nil {
+		switch topology.Scope {
+		case mycrd.TopologyScopeNUMANode, mycrd.TopologyScopePCIRoot, mycrd.TopologyScopePCISwitch:
+		default:
+			return fmt.Errorf("topology: unknown scope '%v'", topology.Scope)
+		}
+		switch topology.Policy {
+		case "", mycrd.TopologyPolicyPrefer, mycrd.TopologyPolicyRequire:
+		default:
+			return fmt.Errorf("topology: unknown policy '%v'", topology.Policy)
+		}
+	}
+
+	return nil
+}
+
+// ValidateMydeviceClassParametersSpec checks class parameters, including what
+// the CRD schema cannot express
+func ValidateMydeviceClassParametersSpec(spec *MydeviceClassParametersSpec) error {
+	err := ValidateMydeviceSelectors(spec.MydeviceSelector)
+	if err != nil {
+		return err
+	}
+
+	for idx, selector := range spec.MydeviceSelector {
+		if selector.Type != "" && selector.Type != MydeviceSelectorWildcard && !IsKnownMydeviceType(MydeviceType(selector.Type)) {
+			return fmt.Errorf("selector %d: unknown device type '%v' never matches", idx, selector.Type)
+		}
+	}
+
+	if spec.MaxSharers < 0 {
+		return fmt.Errorf("maxSharers must not be negative, got %v", spec.MaxSharers)
+	}
+
+	switch spec.AllocationPolicy {
+	case "", mycrd.AllocationPolicyPack, mycrd.AllocationPolicySpread:
+	default:
+		return fmt.Errorf("unknown allocationPolicy '%v'", spec.AllocationPolicy)
+	}
+
+	return nil
+}
+
+// DeviceMatchesClaim returns true if the device is of the requested type, matches
+// the claim attribute selector and the resource class selectors. Nil class
+// parameters do not restrict devices.
+func DeviceMatchesClaim(device *AllocatableMydevice, claimParamsSpec *MydeviceClaimParametersSpec, classParamsSpec *MydeviceClassParametersSpec) bool {
+	requestedType := claimParamsSpec.Type
+	if requestedType == "" {
+		requestedType = mycrd.MydeviceType0
+	}
+	if device.Type != requestedType {
+		return false
+	}
+
+	if !DeviceMatchesClaimSelector(device, claimParamsSpec.Selector) {
+		return false
+	}
+
+	if classParamsSpec == nil {
+		return true
+	}
+
+	return DeviceMatchesSelectors(device, classParamsSpec.MydeviceSelector)
+}
+
+// MaxMatchingDevices returns the largest number of devices on a single node
+// that match the claim and class parameters, and how many nodes publish devices at all.
+func MaxMatchingDevices(mass []mycrd.MydeviceAllocationState, claimParamsSpec *MydeviceClaimParametersSpec, classParamsSpec *MydeviceClassParametersSpec) (int, int) {
+	maxMatching := 0
+	nodes := 0
+	for _, mas := range mass {
+		if len(mas.Spec.AllocatableMydevices) == 0 {
+			continue
+		}
+		nodes++
+
+		matching := 0
+		for _, device := range mas.Spec.AllocatableMydevices {
+			device := device
+			if DeviceMatchesClaim(&device, claimParamsSpec, classParamsSpec) {
+				matching++
+			}
+		}
+		if matching > maxMatching {
+			maxMatching = matching
+		}
+	}
+	return maxMatching, nodes
+}
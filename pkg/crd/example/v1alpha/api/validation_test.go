@@ -0,0 +1,103 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+
+	mycrd "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/v1alpha"
+)
+
+func TestValidateMydeviceClaimParametersSpec(t *testing.T) {
+	numa := &mycrd.MydeviceTopologyConstraint{Scope: mycrd.TopologyScopeNUMANode}
+
+	testCases := []struct {
+		name  string
+		spec  MydeviceClaimParametersSpec
+		valid bool
+	}{
+		{"count", MydeviceClaimParametersSpec{Count: 2}, true},
+		{"count at limit", MydeviceClaimParametersSpec{Count: MaxClaimCount}, true},
+		{"count missing", MydeviceClaimParametersSpec{}, false},
+		{"count over limit", MydeviceClaimParametersSpec{Count: MaxClaimCount + 1}, false},
+		{"minCount without maxCount", MydeviceClaimParametersSpec{Count: 2, MinCount: 1}, false},
+		{"maxCount", MydeviceClaimParametersSpec{MaxCount: 4}, true},
+		{"count range", MydeviceClaimParametersSpec{MinCount: 2, MaxCount: 4}, true},
+		{"single count range", MydeviceClaimParametersSpec{MinCount: 3, MaxCount: 3}, true},
+		{"count and maxCount", MydeviceClaimParametersSpec{Count: 2, MaxCount: 4}, false},
+		{"inverted count range", MydeviceClaimParametersSpec{MinCount: 4, MaxCount: 2}, false},
+		{"maxCount over limit", MydeviceClaimParametersSpec{MinCount: 1, MaxCount: MaxClaimCount + 1}, false},
+		{"known type", MydeviceClaimParametersSpec{Count: 1, Type: mycrd.MydeviceType0}, true},
+		{"unknown type", MydeviceClaimParametersSpec{Count: 1, Type: "type1"}, false},
+		{"selector", MydeviceClaimParametersSpec{Count: 1, Selector: &mycrd.MydeviceClaimSelector{VendorID: "0x8086", DeviceID: "56a0", PCIAddressPrefix: "0000:03"}}, true},
+		{"malformed vendorID", MydeviceClaimParametersSpec{Count: 1, Selector: &mycrd.MydeviceClaimSelector{VendorID: "intel"}}, false},
+		{"malformed deviceID", MydeviceClaimParametersSpec{Count: 1, Selector: &mycrd.MydeviceClaimSelector{DeviceID: "0x56a00"}}, false},
+		{"malformed pciAddressPrefix", MydeviceClaimParametersSpec{Count: 1, Selector: &mycrd.MydeviceClaimSelector{PCIAddressPrefix: "0000/03"}}, false},
+		{"topology with count", MydeviceClaimParametersSpec{Count: 2, Topology: numa}, true},
+		{"topology with count range", MydeviceClaimParametersSpec{MinCount: 2, MaxCount: 4, Topology: numa}, true},
+		{"topology with invalid count range", MydeviceClaimParametersSpec{Count: 2, MaxCount: 4, Topology: numa}, false},
+		{"topology PCIRoot required", MydeviceClaimParametersSpec{Count: 2, Topology: &mycrd.MydeviceTopologyConstraint{Scope: mycrd.TopologyScopePCIRoot, Policy: mycrd.TopologyPolicyRequire}}, true},
+		{"topology PCISwitch preferred", MydeviceClaimParametersSpec{Count: 2, Topology: &mycrd.MydeviceTopologyConstraint{Scope: mycrd.TopologyScopePCISwitch, Policy: mycrd.TopologyPolicyPrefer}}, true},
+		{"topology without scope", MydeviceClaimParametersSpec{Count: 2, Topology: &mycrd.MydeviceTopologyConstraint{Policy: mycrd.TopologyPolicyRequire}}, false},
+		{"topology unknown scope", MydeviceClaimParametersSpec{Count: 2, Topology: &mycrd.MydeviceTopologyConstraint{Scope: "Socket"}}, false},
+		{"topology unknown policy", MydeviceClaimParametersSpec{Count: 2, Topology: &mycrd.MydeviceTopologyConstraint{Scope: mycrd.TopologyScopeNUMANode, Policy: "Always"}}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateMydeviceClaimParametersSpec(&tc.spec)
+			if tc.valid && err != nil {
+				t.Errorf("expected valid spec, got %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Errorf("expected invalid spec")
+			}
+		})
+	}
+}
+
+func TestValidateMydeviceClassParametersSpec(t *testing.T) {
+	testCases := []struct {
+		name  string
+		spec  MydeviceClassParametersSpec
+		valid bool
+	}{
+		{"empty", MydeviceClassParametersSpec{}, true},
+		{"default", *DefaultDeviceClassParametersSpec(), true},
+		{"selector pattern", MydeviceClassParametersSpec{MydeviceSelector: []MydeviceSelector{{Type: mycrd.MydeviceType0, Name: "0000:03:*"}}}, true},
+		{"selector without type", MydeviceClassParametersSpec{MydeviceSelector: []MydeviceSelector{{Name: "*"}}}, true},
+		{"malformed selector pattern", MydeviceClassParametersSpec{MydeviceSelector: []MydeviceSelector{{Name: "[0-"}}}, false},
+		{"unknown selector type", MydeviceClassParametersSpec{MydeviceSelector: []MydeviceSelector{{Type: "type1", Name: "*"}}}, false},
+		{"shared", MydeviceClassParametersSpec{MaxSharers: 4}, true},
+		{"negative maxSharers", MydeviceClassParametersSpec{MaxSharers: -1}, false},
+		{"pack", MydeviceClassParametersSpec{AllocationPolicy: mycrd.AllocationPolicyPack}, true},
+		{"spread", MydeviceClassParametersSpec{AllocationPolicy: mycrd.AllocationPolicySpread}, true},
+		{"unknown allocationPolicy", MydeviceClassParametersSpec{AllocationPolicy: "Random"}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateMydeviceClassParametersSpec(&tc.spec)
+			if tc.valid && err != nil {
+				t.Errorf("expected valid spec, got %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Errorf("expected invalid spec")
+			}
+		})
+	}
+}
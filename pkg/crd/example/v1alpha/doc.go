@@ -18,10 +18,10 @@ limitations under the License.
 //go:generate controller-gen object:headerFile=../../../../hack/boilerplate.go.txt,year=2022 paths=./ output:object:dir=./
 
 //go:generate rm -rf ../../../../deployments/static/crds
-//go:generate controller-gen crd:crdVersions=v1 paths=./ output:crd:dir=../../../../deployments/static/crds
+//go:generate controller-gen crd:crdVersions=v1 paths=../... output:crd:dir=../../../../deployments/static/crds
 
 //go:generate rm -rf ../clientset
-//go:generate client-gen --go-header-file=../../../../hack/boilerplate.go.txt --clientset-name "versioned" --build-tag="ignore_autogenerated" --output-package "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/clientset" --input-base "github.com/kubernetes-sigs/dra-example-driver/pkg/crd" --output-base "./tmp_clientset" --input "example/v1alpha" --plural-exceptions="MydeviceClassParameters:MydeviceClassParameters","MydeviceClaimParameters:MydeviceClaimParameters"
+//go:generate client-gen --go-header-file=../../../../hack/boilerplate.go.txt --clientset-name "versioned" --build-tag="ignore_autogenerated" --output-package "github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/clientset" --input-base "github.com/kubernetes-sigs/dra-example-driver/pkg/crd" --output-base "./tmp_clientset" --input "example/v1alpha,example/v1beta1" --plural-exceptions="MydeviceClassParameters:MydeviceClassParameters","MydeviceClaimParameters:MydeviceClaimParameters"
 
 //go:generate mv ./tmp_clientset/github.com/kubernetes-sigs/dra-example-driver/pkg/crd/example/clientset ../clientset
 //go:generate rm -rf ./tmp_clientset
@@ -28,9 +28,40 @@ const (
 
 // AllocatableMydevice represents an allocatable device on a node
 type AllocatableMydevice struct {
-	CDIDevice string       `json:"cdiDevice"`
-	Type      MydeviceType `json:"type"`
-	UID       string       `json:"uid"` // PCI_DBDF-PCI_DEVICE_ID
+	CDIDevice  string       `json:"cdiDevice"`
+	Type       MydeviceType `json:"type"`
+	UID        string       `json:"uid"`                  // PCI_DBDF-PCI_DEVICE_ID
+	VendorID   string       `json:"vendorID,omitempty"`   // PCI vendor ID, e.g. 0x8086
+	DeviceID   string       `json:"deviceID,omitempty"`   // PCI device ID, e.g. 0x56a0
+	PCIAddress string       `json:"pciAddress,omitempty"` // PCI DBDF, e.g. 0000:03:00.0
+	// Maximum number of claims the device can be shared with, 1 means exclusive.
+	// 0 leaves it to the resource class, which makes devices exclusive by default.
+	// +kubebuilder:validation:Minimum=0
+	MaxSharers int `json:"maxSharers,omitempty"`
+	// +optional
+	Topology *MydeviceTopology `json:"topology,omitempty"`
+	// Set by the node plugin health checker, empty means healthy
+	// +optional
+	Health MydeviceHealth `json:"health,omitempty"`
+	// Set by admins to keep new claims off the device, claims already allocated keep it
+	// +optional
+	Cordoned bool `json:"cordoned,omitempty"`
+}
+
+// MydeviceHealth tells whether a device can take new allocations
+// +kubebuilder:validation:Enum=Healthy;Unhealthy
+type MydeviceHealth string
+
+const (
+	MydeviceHealthy   MydeviceHealth = "Healthy"
+	MydeviceUnhealthy MydeviceHealth = "Unhealthy"
+)
+
+// MydeviceTopology describes where the device is attached on the node
+type MydeviceTopology struct {
+	NUMANode     int    `json:"numaNode"`               // -1 if unknown
+	PCIRoot      string `json:"pciRoot,omitempty"`      // PCI root complex, e.g. pci0000:00
+	ParentBridge string `json:"parentBridge,omitempty"` // PCI address of upstream bridge or switch port
 }
 
 // AllocatedMydevice represents an allocated device on a node
@@ -38,6 +69,9 @@ type AllocatedMydevice struct {
 	CDIDevice string       `json:"cdiDevice"`
 	Type      MydeviceType `json:"type"`
 	UID       string       `json:"uid"`
+	// Maximum number of claims the device can be shared with, 0 or 1 means exclusive
+	// +kubebuilder:validation:Minimum=0
+	MaxSharers int `json:"maxSharers,omitempty"`
 }
 
 // AllocatedMydevices represents a list of allocated devices on a node
@@ -50,6 +84,9 @@ type MydeviceType string
 // RequestedMydevice represents a Mydevice being requested for allocation
 type RequestedMydevice struct {
 	UID string `json:"uid,omitempty"`
+	// Maximum number of claims the device can be shared with, 0 or 1 means exclusive
+	// +kubebuilder:validation:Minimum=0
+	MaxSharers int `json:"maxSharers,omitempty"`
 }
 
 // RequestedMydevices represents a set of request spec and devices requested for allocation
@@ -57,6 +94,13 @@ type RequestedMydevices struct {
 	Spec MydeviceClaimParametersSpec `json:"spec"`
 	// +kubebuilder:validation:MaxItems=8
 	Mydevices []RequestedMydevice `json:"mydevices"`
+	// Number of devices picked, between minCount and maxCount of the spec
+	// +optional
+	AllocatedCount int `json:"allocatedCount,omitempty"`
+	// Other claims of the pod that are allocated together with this one, kept
+	// while the request is pending
+	// +optional
+	Siblings []string `json:"siblings,omitempty"`
 }
 
 // MydeviceAllocationStateSpec is the spec for the MydeviceAllocationState CRD
@@ -22,11 +22,66 @@ import (
 
 // MydeviceClaimParametersSpec is the spec for the DeviceClaimParameters CRD
 type MydeviceClaimParametersSpec struct {
+	// Exact number of devices, not used if MaxCount is set
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:validation:Maximum=8
-	Count int `json:"count"` // quantity of units
+	// +optional
+	Count int `json:"count,omitempty"` // quantity of units
+	// Fewest devices the claim can work with if MaxCount is set, 1 if not set
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=8
+	// +optional
+	MinCount int `json:"minCount,omitempty"`
+	// Allocate as many free devices as there are, up to MaxCount and at least MinCount
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=8
+	// +optional
+	MaxCount int `json:"maxCount,omitempty"`
 	// +kubebuilder:validation:
 	Type MydeviceType `json:"type,omitempty"`
+	// Priority of the claim when competing for devices, taken from the consuming pod if not set.
+	// It is capped at the priority of the consuming pod, claims without pod at 0.
+	// A claim that does not fit can preempt allocated claims of lower priority.
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
+	// +optional
+	Selector *MydeviceClaimSelector `json:"selector,omitempty"`
+	// +optional
+	Topology *MydeviceTopologyConstraint `json:"topology,omitempty"`
+}
+
+// Scopes within which devices of a claim should be located
+const (
+	TopologyScopeNUMANode  = "NUMANode"
+	TopologyScopePCIRoot   = "PCIRoot"
+	TopologyScopePCISwitch = "PCISwitch"
+)
+
+// Whether devices out of the topology scope can be used
+const (
+	TopologyPolicyPrefer  = "Prefer"
+	TopologyPolicyRequire = "Require"
+)
+
+// +kubebuilder:validation:Enum=NUMANode;PCIRoot;PCISwitch
+type TopologyScope string
+
+// +kubebuilder:validation:Enum=Prefer;Require
+type TopologyPolicy string
+
+// MydeviceTopologyConstraint asks for all devices of a claim to share a NUMA node or PCI switch
+type MydeviceTopologyConstraint struct {
+	Scope TopologyScope `json:"scope"`
+	// +optional
+	Policy TopologyPolicy `json:"policy,omitempty"` // Prefer if not set
+}
+
+// MydeviceClaimSelector narrows allocatable devices down by their attributes.
+// All non-empty fields must match.
+type MydeviceClaimSelector struct {
+	VendorID         string `json:"vendorID,omitempty"`
+	DeviceID         string `json:"deviceID,omitempty"`
+	PCIAddressPrefix string `json:"pciAddressPrefix,omitempty"`
 }
 
 // +genclient
@@ -26,9 +26,24 @@ type MydeviceSelector struct {
 	Name string `json:"name"`
 }
 
+// Node selection policies for immediate allocation
+const (
+	AllocationPolicyPack   = "Pack"   // most allocated nodes first
+	AllocationPolicySpread = "Spread" // least allocated nodes first
+)
+
+// +kubebuilder:validation:Enum=Pack;Spread
+type AllocationPolicy string
+
 // MydeviceClassParametersSpec is the spec for the DeviceClassParametersSpec CRD
 type MydeviceClassParametersSpec struct {
 	MydeviceSelector []MydeviceSelector `json:"mydeviceSelector,omitempty"`
+	// Maximum number of claims of this class that can share a device, 0 or 1 means exclusive
+	// +kubebuilder:validation:Minimum=0
+	MaxSharers int `json:"maxSharers,omitempty"`
+	// How nodes are ordered for immediate allocation, first fitting node is used if not set
+	// +optional
+	AllocationPolicy AllocationPolicy `json:"allocationPolicy,omitempty"`
 }
 
 // +genclient
@@ -53,6 +53,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&MydeviceClaimParametersList{},
 		&MydeviceAllocationState{},
 		&MydeviceAllocationStateList{},
+		&MydeviceQuota{},
+		&MydeviceQuotaList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
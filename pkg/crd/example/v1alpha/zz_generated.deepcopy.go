@@ -28,6 +28,11 @@ import (
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AllocatableMydevice) DeepCopyInto(out *AllocatableMydevice) {
 	*out = *in
+	if in.Topology != nil {
+		in, out := &in.Topology, &out.Topology
+		*out = new(MydeviceTopology)
+		**out = **in
+	}
 	return
 }
 
@@ -144,7 +149,7 @@ func (in *MydeviceAllocationStateSpec) DeepCopyInto(out *MydeviceAllocationState
 		in, out := &in.AllocatableMydevices, &out.AllocatableMydevices
 		*out = make(map[string]AllocatableMydevice, len(*in))
 		for key, val := range *in {
-			(*out)[key] = val
+			(*out)[key] = *val.DeepCopy()
 		}
 	}
 	if in.ResourceClaimAllocations != nil {
@@ -187,7 +192,7 @@ func (in *MydeviceClaimParameters) DeepCopyInto(out *MydeviceClaimParameters) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	return
 }
 
@@ -245,6 +250,21 @@ func (in *MydeviceClaimParametersList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MydeviceClaimParametersSpec) DeepCopyInto(out *MydeviceClaimParametersSpec) {
 	*out = *in
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(MydeviceClaimSelector)
+		**out = **in
+	}
+	if in.Topology != nil {
+		in, out := &in.Topology, &out.Topology
+		*out = new(MydeviceTopologyConstraint)
+		**out = **in
+	}
 	return
 }
 
@@ -258,6 +278,22 @@ func (in *MydeviceClaimParametersSpec) DeepCopy() *MydeviceClaimParametersSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MydeviceClaimSelector) DeepCopyInto(out *MydeviceClaimSelector) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MydeviceClaimSelector.
+func (in *MydeviceClaimSelector) DeepCopy() *MydeviceClaimSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(MydeviceClaimSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MydeviceClassParameters) DeepCopyInto(out *MydeviceClassParameters) {
 	*out = *in
@@ -339,6 +375,113 @@ func (in *MydeviceClassParametersSpec) DeepCopy() *MydeviceClassParametersSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MydeviceQuota) DeepCopyInto(out *MydeviceQuota) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MydeviceQuota.
+func (in *MydeviceQuota) DeepCopy() *MydeviceQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(MydeviceQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MydeviceQuota) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MydeviceQuotaList) DeepCopyInto(out *MydeviceQuotaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MydeviceQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MydeviceQuotaList.
+func (in *MydeviceQuotaList) DeepCopy() *MydeviceQuotaList {
+	if in == nil {
+		return nil
+	}
+	out := new(MydeviceQuotaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MydeviceQuotaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MydeviceQuotaSpec) DeepCopyInto(out *MydeviceQuotaSpec) {
+	*out = *in
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = make(map[MydeviceType]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MydeviceQuotaSpec.
+func (in *MydeviceQuotaSpec) DeepCopy() *MydeviceQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MydeviceQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MydeviceQuotaStatus) DeepCopyInto(out *MydeviceQuotaStatus) {
+	*out = *in
+	if in.Used != nil {
+		in, out := &in.Used, &out.Used
+		*out = make(map[MydeviceType]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MydeviceQuotaStatus.
+func (in *MydeviceQuotaStatus) DeepCopy() *MydeviceQuotaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MydeviceQuotaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MydeviceSelector) DeepCopyInto(out *MydeviceSelector) {
 	*out = *in
@@ -355,6 +498,38 @@ func (in *MydeviceSelector) DeepCopy() *MydeviceSelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MydeviceTopology) DeepCopyInto(out *MydeviceTopology) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MydeviceTopology.
+func (in *MydeviceTopology) DeepCopy() *MydeviceTopology {
+	if in == nil {
+		return nil
+	}
+	out := new(MydeviceTopology)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MydeviceTopologyConstraint) DeepCopyInto(out *MydeviceTopologyConstraint) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MydeviceTopologyConstraint.
+func (in *MydeviceTopologyConstraint) DeepCopy() *MydeviceTopologyConstraint {
+	if in == nil {
+		return nil
+	}
+	out := new(MydeviceTopologyConstraint)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RequestedMydevice) DeepCopyInto(out *RequestedMydevice) {
 	*out = *in
@@ -374,12 +549,17 @@ func (in *RequestedMydevice) DeepCopy() *RequestedMydevice {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RequestedMydevices) DeepCopyInto(out *RequestedMydevices) {
 	*out = *in
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	if in.Mydevices != nil {
 		in, out := &in.Mydevices, &out.Mydevices
 		*out = make([]RequestedMydevice, len(*in))
 		copy(*out, *in)
 	}
+	if in.Siblings != nil {
+		in, out := &in.Siblings, &out.Siblings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -0,0 +1,25 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate rm -f zz_generated.deepcopy.go
+//go:generate controller-gen object:headerFile=../../../../hack/boilerplate.go.txt,year=2022 paths=./ output:object:dir=./
+
+// CRDs and clientset of all versions are generated from ../v1alpha
+
+// +k8s:deepcopy-gen=package
+// +groupName=dra.example.com
+
+package v1beta1
@@ -0,0 +1,171 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Types of Devices that can be allocated
+const (
+	MydeviceType0 = "type0"
+)
+
+// Well known keys of AllocatableMydevice.Attributes
+const (
+	AttributeVendorID   = "vendorID"   // PCI vendor ID, e.g. 0x8086
+	AttributeDeviceID   = "deviceID"   // PCI device ID, e.g. 0x56a0
+	AttributePCIAddress = "pciAddress" // PCI DBDF, e.g. 0000:03:00.0
+)
+
+// Condition types of MydeviceAllocationStateStatus
+const (
+	// Node plugin has published its devices and prepares claims allocated on the node
+	MydeviceAllocationStateReady = "Ready"
+)
+
+// MydeviceType is not an enum in this version, unknown types are rejected by the driver
+type MydeviceType string
+
+// AllocatableMydevice represents an allocatable device on a node
+type AllocatableMydevice struct {
+	UID       string       `json:"uid"` // PCI_DBDF-PCI_DEVICE_ID
+	Type      MydeviceType `json:"type"`
+	CDIDevice string       `json:"cdiDevice"`
+	// Properties of the device to select it by, see Attribute* constants for well known keys
+	// +optional
+	Attributes map[string]string `json:"attributes,omitempty"`
+	// Maximum number of claims the device can be shared with, 1 means exclusive.
+	// 0 leaves it to the resource class, which makes devices exclusive by default.
+	// +kubebuilder:validation:Minimum=0
+	MaxSharers int `json:"maxSharers,omitempty"`
+	// +optional
+	Topology *MydeviceTopology `json:"topology,omitempty"`
+	// Set by the node plugin health checker, empty means healthy
+	// +optional
+	Health MydeviceHealth `json:"health,omitempty"`
+	// Set by admins to keep new claims off the device, claims already allocated keep it
+	// +optional
+	Cordoned bool `json:"cordoned,omitempty"`
+}
+
+// MydeviceHealth tells whether a device can take new allocations
+// +kubebuilder:validation:Enum=Healthy;Unhealthy
+type MydeviceHealth string
+
+const (
+	MydeviceHealthy   MydeviceHealth = "Healthy"
+	MydeviceUnhealthy MydeviceHealth = "Unhealthy"
+)
+
+// MydeviceTopology describes where the device is attached on the node
+type MydeviceTopology struct {
+	NUMANode     int    `json:"numaNode"`               // -1 if unknown
+	PCIRoot      string `json:"pciRoot,omitempty"`      // PCI root complex, e.g. pci0000:00
+	ParentBridge string `json:"parentBridge,omitempty"` // PCI address of upstream bridge or switch port
+}
+
+// AllocatedMydevice represents an allocated device on a node
+type AllocatedMydevice struct {
+	UID       string       `json:"uid"`
+	Type      MydeviceType `json:"type"`
+	CDIDevice string       `json:"cdiDevice"`
+	// Maximum number of claims the device can be shared with, 0 or 1 means exclusive
+	// +kubebuilder:validation:Minimum=0
+	MaxSharers int `json:"maxSharers,omitempty"`
+}
+
+// RequestedMydevice represents a Mydevice being requested for allocation
+type RequestedMydevice struct {
+	UID string `json:"uid"`
+	// Maximum number of claims the device can be shared with, 0 or 1 means exclusive
+	// +kubebuilder:validation:Minimum=0
+	MaxSharers int `json:"maxSharers,omitempty"`
+}
+
+// MydeviceClaimAllocation lists the devices allocated to a resource claim
+type MydeviceClaimAllocation struct {
+	ClaimUID string `json:"claimUID"`
+	// +kubebuilder:validation:MaxItems=8
+	Devices []AllocatedMydevice `json:"devices"`
+}
+
+// MydeviceClaimRequest lists the devices picked for a resource claim that is not allocated yet
+type MydeviceClaimRequest struct {
+	ClaimUID string                      `json:"claimUID"`
+	Spec     MydeviceClaimParametersSpec `json:"spec"`
+	// +kubebuilder:validation:MaxItems=8
+	Devices []RequestedMydevice `json:"devices"`
+	// Number of devices picked, between minCount and maxCount of the spec
+	// +optional
+	AllocatedCount int `json:"allocatedCount,omitempty"`
+	// Other claims of the pod that are allocated together with this one, kept
+	// while the request is pending
+	// +optional
+	Siblings []string `json:"siblings,omitempty"`
+}
+
+// MydeviceAllocationStateSpec is the spec for the MydeviceAllocationState CRD
+type MydeviceAllocationStateSpec struct {
+	// +listType=map
+	// +listMapKey=uid
+	// +optional
+	AllocatableDevices []AllocatableMydevice `json:"allocatableDevices,omitempty"`
+	// +listType=map
+	// +listMapKey=claimUID
+	// +optional
+	Allocations []MydeviceClaimAllocation `json:"allocations,omitempty"`
+	// +listType=map
+	// +listMapKey=claimUID
+	// +optional
+	Requests []MydeviceClaimRequest `json:"requests,omitempty"`
+}
+
+// MydeviceAllocationStateStatus is the status for the MydeviceAllocationState CRD
+type MydeviceAllocationStateStatus struct {
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:resource:singular=mas
+// +kubebuilder:storageversion
+
+// MydeviceAllocationState holds the state required for allocation on a node
+type MydeviceAllocationState struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MydeviceAllocationStateSpec   `json:"spec,omitempty"`
+	Status MydeviceAllocationStateStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MydeviceAllocationStateList represents the "plural" of a MydeviceAllocationState CRD object
+type MydeviceAllocationStateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []MydeviceAllocationState `json:"items"`
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MydeviceSelector allows one to match on a specific type of Device as part of the class
+type MydeviceSelector struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// Node selection policies for immediate allocation
+const (
+	AllocationPolicyPack   = "Pack"   // most allocated nodes first
+	AllocationPolicySpread = "Spread" // least allocated nodes first
+)
+
+// +kubebuilder:validation:Enum=Pack;Spread
+type AllocationPolicy string
+
+// MydeviceClassParametersSpec is the spec for the DeviceClassParametersSpec CRD
+type MydeviceClassParametersSpec struct {
+	// Devices of the class have to match all selectors
+	// +optional
+	Selectors []MydeviceSelector `json:"selectors,omitempty"`
+	// Maximum number of claims of this class that can share a device, 0 or 1 means exclusive
+	// +kubebuilder:validation:Minimum=0
+	MaxSharers int `json:"maxSharers,omitempty"`
+	// How nodes are ordered for immediate allocation, first fitting node is used if not set
+	// +optional
+	AllocationPolicy AllocationPolicy `json:"allocationPolicy,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:storageversion
+
+// MydeviceClassParameters holds the set of parameters provided when creating a resource class for this driver
+type MydeviceClassParameters struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MydeviceClassParametersSpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MydeviceClassParametersList represents the "plural" of a MydeviceClassParameters CRD object
+type MydeviceClassParametersList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []MydeviceClassParameters `json:"items"`
+}
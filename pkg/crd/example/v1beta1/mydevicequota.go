@@ -0,0 +1,60 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MydeviceQuotaSpec is the spec for the MydeviceQuota CRD
+type MydeviceQuotaSpec struct {
+	// Maximum number of devices of each type allocated to claims in the namespace.
+	// Types that are not listed are not limited.
+	Limits map[MydeviceType]int `json:"limits,omitempty"`
+}
+
+// MydeviceQuotaStatus is the status for the MydeviceQuota CRD
+type MydeviceQuotaStatus struct {
+	// Number of devices of each type currently allocated to claims in the namespace
+	Used map[MydeviceType]int `json:"used,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+
+// MydeviceQuota limits the number of devices that claims in a namespace can hold
+type MydeviceQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MydeviceQuotaSpec   `json:"spec,omitempty"`
+	Status MydeviceQuotaStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MydeviceQuotaList represents the "plural" of a MydeviceQuota CRD object
+type MydeviceQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []MydeviceQuota `json:"items"`
+}